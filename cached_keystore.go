@@ -0,0 +1,138 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CacheOption configures CachedKeyStore.
+type CacheOption func(*KeyStoreCache)
+
+// WithNegativeCaching sets how long a key that the store reports as absent is remembered. The default is the same
+// as the TTL for values. Zero disables negative caching so that absent keys are asked for every time.
+func WithNegativeCaching(ttl time.Duration) CacheOption {
+	return func(c *KeyStoreCache) { c.negativeTTL = ttl }
+}
+
+// KeyStoreCache caches the results of a KeyStore. Use its Lookup method as the KeyStore.
+// It is safe for concurrent use.
+type KeyStoreCache struct {
+	store       KeyStore
+	ttl         time.Duration
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	calls   map[string]*cacheCall
+}
+
+type cacheEntry struct {
+	value   string
+	present bool
+	expires time.Time
+}
+
+// cacheCall is a lookup in progress, shared by concurrent callers for the same key.
+type cacheCall struct {
+	done    chan struct{}
+	value   string
+	present bool
+	err     error
+}
+
+// CachedKeyStore wraps a store so that each key is looked up at most once per ttl, which saves calls to remote
+// stores when configuration is reloaded. Keys that are absent are also cached unless WithNegativeCaching says
+// otherwise. Errors are never cached. Concurrent lookups of the same key share a single call to the store.
+//
+//	cache := goconfig.CachedKeyStore(vault.Lookup, 5*time.Minute)
+//	err := goconfig.Load(ctx, &cfg, goconfig.WithKeyStore(cache.Lookup))
+func CachedKeyStore(store KeyStore, ttl time.Duration, options ...CacheOption) *KeyStoreCache {
+	cache := &KeyStoreCache{
+		store:       store,
+		ttl:         ttl,
+		negativeTTL: ttl,
+		now:         time.Now,
+		entries:     make(map[string]cacheEntry),
+		calls:       make(map[string]*cacheCall),
+	}
+	for _, opt := range options {
+		opt(cache)
+	}
+	return cache
+}
+
+// Lookup implements KeyStore, returning the cached result if it has not expired.
+func (c *KeyStoreCache) Lookup(ctx context.Context, key string) (string, bool, error) {
+	for {
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok && c.now().Before(entry.expires) {
+			c.mu.Unlock()
+			return entry.value, entry.present, nil
+		}
+		call, inProgress := c.calls[key]
+		if !inProgress {
+			call = &cacheCall{done: make(chan struct{})}
+			c.calls[key] = call
+			c.mu.Unlock()
+			c.call(ctx, key, call)
+			return call.value, call.present, call.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		}
+		// The call failed because its caller gave up, which should not affect this caller
+		if isContextError(call.err) && ctx.Err() == nil {
+			continue
+		}
+		return call.value, call.present, call.err
+	}
+}
+
+// call asks the store for the key and caches the result.
+func (c *KeyStoreCache) call(ctx context.Context, key string, call *cacheCall) {
+	defer close(call.done)
+	call.value, call.present, call.err = c.store(ctx, key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.calls[key] != call {
+		// Invalidated while the call was in progress
+		return
+	}
+	delete(c.calls, key)
+
+	ttl := c.ttl
+	if !call.present {
+		ttl = c.negativeTTL
+	}
+	if call.err == nil && ttl > 0 {
+		c.entries[key] = cacheEntry{value: call.value, present: call.present, expires: c.now().Add(ttl)}
+	}
+}
+
+// Invalidate removes the keys from the cache so that the next lookup asks the store. With no keys the whole cache
+// is cleared. Lookups in progress are not cached.
+func (c *KeyStoreCache) Invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(keys) == 0 {
+		clear(c.entries)
+		clear(c.calls)
+		return
+	}
+	for _, key := range keys {
+		delete(c.entries, key)
+		delete(c.calls, key)
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
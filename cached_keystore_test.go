@@ -0,0 +1,206 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachedKeyStore(t *testing.T) {
+	ctx := context.Background()
+
+	// countingStore counts calls per key. PORT is present, FAIL returns an error and other keys are absent.
+	type countingStore struct {
+		mu    sync.Mutex
+		calls map[string]int
+		port  string
+	}
+	newCountingStore := func() *countingStore {
+		return &countingStore{calls: make(map[string]int), port: "8080"}
+	}
+	lookup := func(s *countingStore) KeyStore {
+		return func(ctx context.Context, key string) (string, bool, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.calls[key]++
+			switch key {
+			case "PORT":
+				return s.port, true, nil
+			case "FAIL":
+				return "", false, errors.New("backend unavailable")
+			}
+			return "", false, nil
+		}
+	}
+	newCache := func(store *countingStore, ttl time.Duration, options ...CacheOption) (*KeyStoreCache, *time.Time) {
+		cache := CachedKeyStore(lookup(store), ttl, options...)
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+		return cache, &now
+	}
+
+	t.Run("Values expire after the TTL", func(t *testing.T) {
+		store := newCountingStore()
+		cache, now := newCache(store, time.Minute)
+
+		for i := 0; i < 3; i++ {
+			if value, ok, err := cache.Lookup(ctx, "PORT"); err != nil || !ok || value != "8080" {
+				t.Fatalf("Lookup() = (%q, %v, %v)", value, ok, err)
+			}
+		}
+		if store.calls["PORT"] != 1 {
+			t.Errorf("Expected 1 call, got %d", store.calls["PORT"])
+		}
+
+		store.port = "9090"
+		*now = now.Add(time.Minute)
+		if value, _, _ := cache.Lookup(ctx, "PORT"); value != "9090" || store.calls["PORT"] != 2 {
+			t.Errorf("Expected a fresh value after expiry, got %q after %d calls", value, store.calls["PORT"])
+		}
+	})
+
+	t.Run("Negative caching", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			options   []CacheOption
+			advance   time.Duration
+			wantCalls int
+		}{
+			{"Cached by default", nil, 20 * time.Second, 1},
+			{"Disabled", []CacheOption{WithNegativeCaching(0)}, 0, 3},
+			{"Shorter TTL", []CacheOption{WithNegativeCaching(10 * time.Second)}, 30 * time.Second, 3},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				store := newCountingStore()
+				cache, now := newCache(store, time.Minute, tt.options...)
+				for i := 0; i < 3; i++ {
+					if _, ok, err := cache.Lookup(ctx, "MISSING"); ok || err != nil {
+						t.Fatalf("Lookup() = (%v, %v), want absent", ok, err)
+					}
+					*now = now.Add(tt.advance)
+				}
+				if store.calls["MISSING"] != tt.wantCalls {
+					t.Errorf("Expected %d calls, got %d", tt.wantCalls, store.calls["MISSING"])
+				}
+			})
+		}
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		store := newCountingStore()
+		cache, _ := newCache(store, time.Minute)
+		for i := 0; i < 2; i++ {
+			if _, _, err := cache.Lookup(ctx, "FAIL"); err == nil {
+				t.Fatal("Expected error")
+			}
+		}
+		if store.calls["FAIL"] != 2 {
+			t.Errorf("Expected 2 calls, got %d", store.calls["FAIL"])
+		}
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		store := newCountingStore()
+		cache, _ := newCache(store, time.Minute)
+		_, _, _ = cache.Lookup(ctx, "PORT")
+		_, _, _ = cache.Lookup(ctx, "MISSING")
+
+		cache.Invalidate("PORT")
+		_, _, _ = cache.Lookup(ctx, "PORT")
+		_, _, _ = cache.Lookup(ctx, "MISSING")
+		if store.calls["PORT"] != 2 || store.calls["MISSING"] != 1 {
+			t.Errorf("Expected only PORT to be fetched again, got %v", store.calls)
+		}
+
+		cache.Invalidate()
+		_, _, _ = cache.Lookup(ctx, "PORT")
+		_, _, _ = cache.Lookup(ctx, "MISSING")
+		if store.calls["PORT"] != 3 || store.calls["MISSING"] != 2 {
+			t.Errorf("Expected both keys to be fetched again, got %v", store.calls)
+		}
+	})
+
+	t.Run("Concurrent lookups share a call", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		cache := CachedKeyStore(func(ctx context.Context, key string) (string, bool, error) {
+			calls.Add(1)
+			<-release
+			return "value", true, nil
+		}, time.Minute)
+
+		var wg sync.WaitGroup
+		results := make(chan string, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, _, _ := cache.Lookup(ctx, "KEY")
+				results <- value
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		close(results)
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected 1 call, got %d", calls.Load())
+		}
+		for value := range results {
+			if value != "value" {
+				t.Errorf("Expected shared value, got %q", value)
+			}
+		}
+	})
+
+	t.Run("Waiters honour their context", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		cache := CachedKeyStore(func(ctx context.Context, key string) (string, bool, error) {
+			<-release
+			return "value", true, nil
+		}, time.Minute)
+
+		go func() { _, _, _ = cache.Lookup(ctx, "KEY") }()
+		time.Sleep(10 * time.Millisecond)
+
+		cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if _, _, err := cache.Lookup(cancelled, "KEY"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("Invalidated call is not cached", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var calls atomic.Int32
+		cache := CachedKeyStore(func(ctx context.Context, key string) (string, bool, error) {
+			if calls.Add(1) == 1 {
+				close(started)
+				<-release
+				return "stale", true, nil
+			}
+			return "fresh", true, nil
+		}, time.Minute)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _, _ = cache.Lookup(ctx, "KEY")
+		}()
+		<-started
+		cache.Invalidate("KEY")
+		close(release)
+		<-done
+
+		if value, _, _ := cache.Lookup(ctx, "KEY"); value != "fresh" {
+			t.Errorf("Expected fresh value, got %q", value)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/m0rjc/goconfig/internal/readpipeline"
 )
@@ -32,8 +33,11 @@ import (
 //   - Validators run after type conversion but before field assignment
 //
 // Options:
+//   - WithKeyStore(store): Read values from an alternative KeyStore
+//   - WithCustomType(handler): Register a custom type handler for this Load
 //   - WithValidator(path, validator): Register custom validator for a specific field
 //   - WithValidatorFactory(factory): Register a custom validator factory
+//   - WithInterpolation(): Expand ${VAR} references to other keys in values and defaults
 //
 // Example:
 //
@@ -44,7 +48,7 @@ import (
 //	}
 //
 //	cfg := Config{}
-//	err := Load(&cfg, WithValidator("Email", emailValidator))
+//	err := Load(ctx, &cfg, WithValidator("Email", emailValidator))
 func Load(ctx context.Context, config interface{}, options ...Option) error {
 	v := reflect.ValueOf(config)
 	if v.Kind() != reflect.Ptr {
@@ -58,9 +62,23 @@ func Load(ctx context.Context, config interface{}, options ...Option) error {
 
 	opts := newLoadOptions()
 	opts.applyOptions(options)
+	if err := opts.checkValidatorPaths(v.Type()); err != nil {
+		return err
+	}
+
+	// Tell the stores which keys will be read so that they can fetch them together
+	scope := structScope{keyPrefix: opts.keyPrefix}
+	fields, err := keyedFields(v, scope, opts)
+	if err != nil {
+		return err
+	}
+	ctx = withLoadSession(ctx, requestedKeys(fields))
+	if opts.interpolate {
+		opts.literalKeys = literalKeys(fields)
+	}
 
 	errors := &ConfigErrors{Errors: make([]ConfigError, 0)}
-	if err := loadStruct(ctx, v, "", opts, errors); err != nil {
+	if err := loadStruct(ctx, v, scope, opts, errors); err != nil {
 		return err // configuration error, fail-fast
 	}
 
@@ -70,56 +88,154 @@ func Load(ctx context.Context, config interface{}, options ...Option) error {
 	return nil
 }
 
-// loadStruct recursively loads configuration values into a struct.
-// fieldPath tracks the current position in the struct hierarchy for validators.
-func loadStruct(ctx context.Context, v reflect.Value, fieldPath string, opts *loadOptions, errors *ConfigErrors) error {
+// structScope describes the position of a struct in the configuration hierarchy.
+type structScope struct {
+	// fieldPath tracks the current position in the struct hierarchy for validators.
+	fieldPath string
+	// keyPrefix is prepended to the keys of all fields beneath this struct.
+	keyPrefix string
+	// fieldNames are the field names since the last prefix, used to derive keys.
+	fieldNames []string
+}
+
+// nested returns the scope for a field within this struct.
+func (s structScope) nested(field reflect.StructField) structScope {
+	fieldPath := field.Name
+	if s.fieldPath != "" {
+		fieldPath = s.fieldPath + "." + field.Name
+	}
+
+	// A prefix replaces the outer field names in derived keys. Embedded structs do not contribute a name.
+	prefix, hasPrefix := field.Tag.Lookup("prefix")
+	var fieldNames []string
+	if !hasPrefix {
+		fieldNames = append(fieldNames, s.fieldNames...)
+		if !field.Anonymous {
+			fieldNames = append(fieldNames, field.Name)
+		}
+	}
+
+	return structScope{
+		fieldPath:  fieldPath,
+		keyPrefix:  s.keyPrefix + prefix,
+		fieldNames: fieldNames,
+	}
+}
+
+// structField is a field of a configuration struct, as found by structFields.
+type structField struct {
+	// value is the field itself, or for a nested struct the struct that the field holds or points to
+	value reflect.Value
+	field reflect.StructField
+	// scope is the scope of the field, which is the scope of the nested struct if nested is true
+	scope structScope
+	// nested is true if value is a nested configuration struct to recurse into
+	nested bool
+	// keys are the keys for a field that is not nested
+	keys fieldKeys
+}
+
+// structFields returns the fields of the struct that are either nested structs or have a key, in field order.
+// Nil pointers to nested structs are allocated. Misconfigured tags are returned as an error.
+func structFields(v reflect.Value, scope structScope, opts *loadOptions) ([]structField, error) {
 	t := v.Type()
+	var fields []structField
 
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)
 		fieldType := t.Field(i)
 
-		// Get the key tag
+		// Get the key tag. A key of "-" excludes the field.
 		key := fieldType.Tag.Get("key")
+		if key == "-" {
+			continue
+		}
 
 		// Skip unexported fields, but error if they have a key tag
 		if !field.CanSet() {
 			if key != "" {
-				return fmt.Errorf("field %s is unexported but has a key tag", fieldType.Name)
+				return nil, fmt.Errorf("field %s is unexported but has a key tag", fieldType.Name)
 			}
 			continue
 		}
 
 		// Build the current field path
-		currentPath := fieldType.Name
-		if fieldPath != "" {
-			currentPath = fieldPath + "." + fieldType.Name
-		}
+		fieldScope := scope.nested(fieldType)
+		currentPath := fieldScope.fieldPath
 
 		if key == "" {
 			// If it's a struct or pointer to struct then recurse into it
-			effectiveField := field
-			if field.Kind() == reflect.Ptr {
-				if field.IsNil() && field.Type().Elem().Kind() == reflect.Struct {
-					field.Set(reflect.New(field.Type().Elem()))
+			if isNestedStruct(fieldType.Type, opts) {
+				effectiveField := field
+				if field.Kind() == reflect.Ptr {
+					if field.IsNil() {
+						field.Set(reflect.New(field.Type().Elem()))
+					}
+					effectiveField = field.Elem()
 				}
-				effectiveField = field.Elem()
+				fields = append(fields, structField{value: effectiveField, field: fieldType, scope: fieldScope, nested: true})
+				continue
 			}
 
-			if effectiveField.Kind() == reflect.Struct {
-				if err := loadStruct(ctx, effectiveField, currentPath, opts, errors); err != nil {
-					return err
-				}
+			// No key tag, so derive one if enabled or skip this field
+			if opts.keyNaming == nil {
+				continue
+			}
+			key = opts.keyNaming(fieldScope.fieldNames)
+		}
+
+		if _, hasPrefix := fieldType.Tag.Lookup("prefix"); hasPrefix {
+			return nil, fmt.Errorf("field %s is not a nested struct so cannot have a prefix tag", currentPath)
+		}
+		keys, err := newFieldKeys(key, fieldType.Tag, scope.keyPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", currentPath, err)
+		}
+		fields = append(fields, structField{value: field, field: fieldType, scope: fieldScope, keys: keys})
+	}
+	return fields, nil
+}
+
+// loadStruct recursively loads configuration values into a struct.
+// Once the fields are populated, the struct's Validate method is called if it has one and no errors were found
+// within the struct.
+func loadStruct(ctx context.Context, v reflect.Value, scope structScope, opts *loadOptions, errors *ConfigErrors) error {
+	errorCount := errors.Len()
+	fieldKeys := make(map[string]string)
+
+	fields, err := structFields(v, scope, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if f.nested {
+			if err := loadStruct(ctx, f.value, f.scope, opts, errors); err != nil {
+				return err
 			}
-			// No key tag, skip this field
 			continue
 		}
 
-		configuredValue, present, err := getConfiguredValue(ctx, fieldType.Tag, key, opts)
+		field, fieldType, currentPath := f.value, f.field, f.scope.fieldPath
+		key := f.keys.primary
+		fieldKeys[fieldType.Name] = key
+
+		configuredValue, source, present, err := getConfiguredValue(ctx, fieldType.Tag, f.keys, opts, errors)
 		if err != nil {
 			return err
 		}
 
+		if present && opts.interpolate && fieldType.Tag.Get("interpolate") != "false" {
+			configuredValue, err = newInterpolator(opts.keyStore, opts.literalKeys).expandValue(ctx, source, configuredValue)
+			if storeErr, ok := err.(*storeError); ok {
+				return storeErr.err
+			}
+			if err != nil {
+				errors.Add(key, err)
+				continue
+			}
+		}
+
 		isKeyRequired := fieldType.Tag.Get("keyRequired") == "true"
 		isValueRequired := fieldType.Tag.Get("required") == "true"
 		if !present {
@@ -141,6 +257,13 @@ func loadStruct(ctx context.Context, v reflect.Value, fieldPath string, opts *lo
 			return fmt.Errorf("setting up field readpipeline %s: %v", currentPath, err)
 		}
 
+		// Add any custom validators registered for this field
+		validators, err := opts.validatorsFor(fieldType, currentPath)
+		if err != nil {
+			return fmt.Errorf("setting up validators for field %s: %v", currentPath, err)
+		}
+		processor = readpipeline.PipeMultiple(processor, validators)
+
 		// Parse the configured value to produce a raw value
 		rawValue, err := processor(configuredValue)
 		if err != nil {
@@ -151,25 +274,147 @@ func loadStruct(ctx context.Context, v reflect.Value, fieldPath string, opts *lo
 		setField(field, rawValue, key, errors)
 	}
 
+	if errors.Len() == errorCount {
+		validateStruct(ctx, v, scope, fieldKeys, errors)
+	}
 	return nil
 }
 
+// isNestedStruct reports whether a field without a key is a nested configuration struct to recurse into.
+// Struct types with a handler of their own, such as url.URL, are values rather than nested configuration, unless
+// they declare keys. Those are always nested, because an UnmarshalText or UnmarshalJSON method promoted from an
+// embedded type would otherwise leave their fields silently unloaded.
+func isNestedStruct(fieldType reflect.Type, opts *loadOptions) bool {
+	if declaresKeys(fieldType, nil) {
+		return true
+	}
+	if opts.typeRegistry.HasTypeHandler(fieldType) {
+		return false
+	}
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.Struct && !opts.typeRegistry.HasTypeHandler(fieldType)
+}
+
+// declaresKeys reports whether the struct, or pointer to struct, has fields with key or prefix tags, either directly
+// or in nested structs without keys. visited guards against recursive types.
+func declaresKeys(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+	if visited == nil {
+		visited = make(map[reflect.Type]bool)
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, hasKey := field.Tag.Lookup("key")
+		_, hasPrefix := field.Tag.Lookup("prefix")
+		switch {
+		case !field.IsExported() || key == "-":
+		case hasPrefix || (hasKey && key != ""):
+			return true
+		case declaresKeys(field.Type, visited):
+			return true
+		}
+	}
+	return false
+}
+
+// fieldKeys are the keys that may supply a field's value.
+type fieldKeys struct {
+	// primary is the key that errors are reported against.
+	primary string
+	// aliases are tried in order if the primary key is not present.
+	aliases []string
+	// deprecated keys are read if no other key is present, with a warning to migrate to the primary key.
+	deprecated []string
+}
+
+// all returns every key that is read for the field.
+func (k fieldKeys) all() []string {
+	keys := append([]string{k.primary}, k.aliases...)
+	return append(keys, k.deprecated...)
+}
+
+// newFieldKeys parses a key tag of the form "KEY,ALIAS,..." and the deprecated tag, applying the key prefix.
+func newFieldKeys(keyTag string, tag reflect.StructTag, prefix string) (fieldKeys, error) {
+	names := splitKeyList(keyTag, prefix)
+	if len(names) == 0 {
+		return fieldKeys{}, fmt.Errorf("key tag has no keys")
+	}
+	keys := fieldKeys{primary: names[0], aliases: names[1:]}
+	if deprecatedTag, ok := tag.Lookup("deprecated"); ok {
+		keys.deprecated = splitKeyList(deprecatedTag, prefix)
+	}
+	return keys, nil
+}
+
+// splitKeyList splits a comma separated list of keys, applying the prefix to each.
+func splitKeyList(list string, prefix string) []string {
+	var keys []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			keys = append(keys, prefix+name)
+		}
+	}
+	return keys
+}
+
 // getConfiguredValue reads the string value to use for the field. This is read from the keystore or
-// any default provided in the tag.
-func getConfiguredValue(ctx context.Context, tag reflect.StructTag, key string, opts *loadOptions) (string, bool, error) {
-	// Get the environment variable value
-	envValue, present, err := opts.keyStore(ctx, key)
-	if present || err != nil {
-		return envValue, present, err
+// any default provided in the tag. The source is the key that supplied the value, or empty for a default.
+// A deprecated key that is set to a different value from the current key is reported as a configuration error.
+func getConfiguredValue(ctx context.Context, tag reflect.StructTag, keys fieldKeys, opts *loadOptions, errors *ConfigErrors) (value string, source string, present bool, err error) {
+	// Get the environment variable value, trying each alias in turn
+	envValue := ""
+	for _, key := range append([]string{keys.primary}, keys.aliases...) {
+		envValue, present, err = opts.keyStore(ctx, key)
+		if err != nil {
+			return "", "", false, err
+		}
+		if present {
+			source = key
+			break
+		}
+	}
+
+	// Deprecated keys are compared with the current keys only. If just deprecated keys are set, the first is used.
+	currentPresent := present
+	for _, deprecatedKey := range keys.deprecated {
+		deprecatedValue, deprecatedPresent, err := opts.keyStore(ctx, deprecatedKey)
+		if err != nil {
+			return "", "", false, err
+		}
+		if !deprecatedPresent {
+			continue
+		}
+		if currentPresent && deprecatedValue != envValue {
+			errors.Add(keys.primary, fmt.Errorf("%w %s", ErrDeprecatedKeyConflict, deprecatedKey))
+			continue
+		}
+		// Warn even if the value is not used, so that old keys are removed from deployments
+		opts.logger.Warn("deprecated configuration key", "key", deprecatedKey, "replacement", keys.primary)
+		if !present {
+			envValue, source, present = deprecatedValue, deprecatedKey, true
+		}
+	}
+
+	if present {
+		return envValue, source, true, nil
 	}
 
 	// Get the default value
 	defaultValue, defaultPresent := tag.Lookup("default")
 	if defaultPresent {
-		return defaultValue, true, nil
+		return defaultValue, "", true, nil
 	}
 
-	return "", false, nil
+	return "", "", false, nil
 }
 
 // setField sets a field value based on its type. It automatically handles pointer fields
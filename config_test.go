@@ -1,12 +1,16 @@
 package goconfig
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log/slog"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad_Basic(t *testing.T) {
@@ -79,6 +83,194 @@ func TestLoad_Nested(t *testing.T) {
 	})
 }
 
+// textLabel decodes itself, so structs that embed it do too.
+type textLabel struct {
+	Label string
+}
+
+func (l *textLabel) UnmarshalText(text []byte) error {
+	l.Label = string(text)
+	return nil
+}
+
+func TestLoad_NestedWithUnmarshaler(t *testing.T) {
+	type DatabaseConfig struct {
+		textLabel
+		URL string `key:"DB_URL"`
+	}
+	type Config struct {
+		DB    DatabaseConfig
+		Label textLabel `key:"LABEL"`
+	}
+	mockStore := func(ctx context.Context, key string) (string, bool, error) {
+		switch key {
+		case "DB_URL":
+			return "postgres://localhost:5432", true, nil
+		case "LABEL":
+			return "primary", true, nil
+		}
+		return "", false, nil
+	}
+
+	var cfg Config
+	if err := Load(context.Background(), &cfg, WithKeyStore(mockStore)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.DB.URL != "postgres://localhost:5432" || cfg.Label.Label != "primary" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+}
+
+func TestLoad_Prefixes(t *testing.T) {
+	type DatabaseConfig struct {
+		Host string `key:"DB_HOST" default:"localhost"`
+		Port int    `key:"DB_PORT"`
+	}
+	type Config struct {
+		Primary DatabaseConfig
+		Replica *DatabaseConfig `prefix:"REPLICA_"`
+		Cluster struct {
+			Backup DatabaseConfig `prefix:"BACKUP_"`
+		} `prefix:"CLUSTER_"`
+	}
+
+	values := map[string]string{
+		"MYAPP_DB_HOST":                "primary",
+		"MYAPP_DB_PORT":                "5432",
+		"MYAPP_REPLICA_DB_HOST":        "replica",
+		"MYAPP_REPLICA_DB_PORT":        "5433",
+		"MYAPP_CLUSTER_BACKUP_DB_PORT": "not a number",
+	}
+	mockStore := func(ctx context.Context, key string) (string, bool, error) {
+		val, ok := values[key]
+		return val, ok, nil
+	}
+
+	var cfg Config
+	err := Load(context.Background(), &cfg, WithKeyStore(mockStore), WithKeyPrefix("MYAPP_"))
+	var cfgErrs *ConfigErrors
+	if !errors.As(err, &cfgErrs) {
+		t.Fatalf("Expected ConfigErrors, got %v", err)
+	}
+	if cfgErrs.Len() != 1 || cfgErrs.Errors[0].Key != "MYAPP_CLUSTER_BACKUP_DB_PORT" {
+		t.Errorf("Unexpected errors: %v", err)
+	}
+	if cfg.Primary.Host != "primary" || cfg.Primary.Port != 5432 {
+		t.Errorf("Unexpected Primary: %+v", cfg.Primary)
+	}
+	if cfg.Replica.Host != "replica" || cfg.Replica.Port != 5433 {
+		t.Errorf("Unexpected Replica: %+v", cfg.Replica)
+	}
+	if cfg.Cluster.Backup.Host != "localhost" {
+		t.Errorf("Unexpected Backup: %+v", cfg.Cluster.Backup)
+	}
+
+	t.Run("Prefix on keyed field", func(t *testing.T) {
+		type Config struct {
+			Port int `key:"PORT" prefix:"X_"`
+		}
+		var cfg Config
+		err := Load(context.Background(), &cfg, WithKeyStore(mockStore))
+		if err == nil || !strings.Contains(err.Error(), "cannot have a prefix tag") {
+			t.Errorf("Expected setup error, got %v", err)
+		}
+	})
+}
+
+func TestLoad_KeyAliases(t *testing.T) {
+	type Config struct {
+		DatabaseURL string `key:"DATABASE_URL,DB_URL" deprecated:"DB_CONNECTION,DB_CONN"`
+	}
+
+	load := func(t *testing.T, values map[string]string) (Config, string, error) {
+		var logs bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&logs, nil))
+		mockStore := func(ctx context.Context, key string) (string, bool, error) {
+			val, ok := values[key]
+			return val, ok, nil
+		}
+		var cfg Config
+		err := Load(context.Background(), &cfg, WithKeyStore(mockStore), WithLogger(logger), WithKeyPrefix("APP_"))
+		return cfg, logs.String(), err
+	}
+
+	t.Run("Primary key wins", func(t *testing.T) {
+		cfg, logs, err := load(t, map[string]string{"APP_DATABASE_URL": "primary", "APP_DB_URL": "alias"})
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cfg.DatabaseURL != "primary" || logs != "" {
+			t.Errorf("Unexpected result %q, logs %q", cfg.DatabaseURL, logs)
+		}
+	})
+
+	t.Run("Alias used without warning", func(t *testing.T) {
+		cfg, logs, err := load(t, map[string]string{"APP_DB_URL": "alias"})
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cfg.DatabaseURL != "alias" || logs != "" {
+			t.Errorf("Unexpected result %q, logs %q", cfg.DatabaseURL, logs)
+		}
+	})
+
+	t.Run("Deprecated key used with warning", func(t *testing.T) {
+		cfg, logs, err := load(t, map[string]string{"APP_DB_CONNECTION": "old"})
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cfg.DatabaseURL != "old" {
+			t.Errorf("Unexpected result %q", cfg.DatabaseURL)
+		}
+		if !strings.Contains(logs, "key=APP_DB_CONNECTION replacement=APP_DATABASE_URL") {
+			t.Errorf("Expected deprecation warning, got %q", logs)
+		}
+	})
+
+	t.Run("Deprecated key with same value", func(t *testing.T) {
+		_, logs, err := load(t, map[string]string{"APP_DATABASE_URL": "same", "APP_DB_CONNECTION": "same"})
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if !strings.Contains(logs, "key=APP_DB_CONNECTION replacement=APP_DATABASE_URL") {
+			t.Errorf("Expected deprecation warning, got %q", logs)
+		}
+	})
+
+	t.Run("Deprecated key with different value", func(t *testing.T) {
+		_, _, err := load(t, map[string]string{"APP_DB_URL": "new", "APP_DB_CONNECTION": "old"})
+		if !errors.Is(err, ErrDeprecatedKeyConflict) {
+			t.Fatalf("Expected conflict error, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "APP_DATABASE_URL: value conflicts with deprecated key APP_DB_CONNECTION") {
+			t.Errorf("Unexpected error message: %v", err)
+		}
+	})
+
+	t.Run("Deprecated keys are not compared with each other", func(t *testing.T) {
+		cfg, logs, err := load(t, map[string]string{"APP_DB_CONNECTION": "old", "APP_DB_CONN": "older"})
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cfg.DatabaseURL != "old" {
+			t.Errorf("Expected the first deprecated key to be used, got %q", cfg.DatabaseURL)
+		}
+		if !strings.Contains(logs, "key=APP_DB_CONN ") {
+			t.Errorf("Expected a warning for each deprecated key, got %q", logs)
+		}
+	})
+
+	t.Run("Empty key tag", func(t *testing.T) {
+		type Config struct {
+			Port int `key:","`
+		}
+		var cfg Config
+		if err := Load(context.Background(), &cfg); err == nil {
+			t.Error("Expected setup error for empty key list")
+		}
+	})
+}
+
 func TestLoad_Pointers(t *testing.T) {
 	type Config struct {
 		Port *int    `key:"PORT"`
@@ -112,6 +304,78 @@ func TestLoad_Pointers(t *testing.T) {
 	}
 }
 
+func TestLoad_Slices(t *testing.T) {
+	type Config struct {
+		Hosts   []string         `key:"HOSTS" unique:"true"`
+		Ports   []int            `key:"PORTS" sep:";" min:"1024"`
+		Retries *[]time.Duration `key:"RETRIES" default:"1s,5s"`
+		Key     []byte           `key:"KEY"`
+	}
+
+	mockStore := func(ctx context.Context, key string) (string, bool, error) {
+		switch key {
+		case "HOSTS":
+			return "a.example.com, b.example.com", true, nil
+		case "KEY":
+			return "hello", true, nil
+		case "PORTS":
+			return "8080;8081", true, nil
+		}
+		return "", false, nil
+	}
+
+	var cfg Config
+	if err := Load(context.Background(), &cfg, WithKeyStore(mockStore)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Hosts, []string{"a.example.com", "b.example.com"}) {
+		t.Errorf("Unexpected Hosts: %v", cfg.Hosts)
+	}
+	if !reflect.DeepEqual(cfg.Ports, []int{8080, 8081}) {
+		t.Errorf("Unexpected Ports: %v", cfg.Ports)
+	}
+	if cfg.Retries == nil || !reflect.DeepEqual(*cfg.Retries, []time.Duration{time.Second, 5 * time.Second}) {
+		t.Errorf("Unexpected Retries: %v", cfg.Retries)
+	}
+	if string(cfg.Key) != "hello" {
+		t.Errorf("Unexpected Key: %v", cfg.Key)
+	}
+
+	t.Run("JSON array from the environment with format json", func(t *testing.T) {
+		t.Setenv("TEST_SLICE_HOSTS", `["a;b", " c "]`)
+		t.Setenv("TEST_SLICE_SPLIT", `["a";"b"]`)
+		type EnvConfig struct {
+			Hosts []string `key:"TEST_SLICE_HOSTS" format:"json"`
+			Split []string `key:"TEST_SLICE_SPLIT" sep:";"`
+		}
+		var cfg EnvConfig
+		if err := Load(context.Background(), &cfg); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if !reflect.DeepEqual(cfg.Hosts, []string{"a;b", " c "}) || !reflect.DeepEqual(cfg.Split, []string{`["a"`, `"b"]`}) {
+			t.Errorf("Unexpected config: %q", cfg)
+		}
+	})
+
+	t.Run("Element validation error", func(t *testing.T) {
+		badStore := func(ctx context.Context, key string) (string, bool, error) {
+			if key == "PORTS" {
+				return "8080;80", true, nil
+			}
+			return "", false, nil
+		}
+		var cfg Config
+		err := Load(context.Background(), &cfg, WithKeyStore(badStore))
+		var cfgErrs *ConfigErrors
+		if !errors.As(err, &cfgErrs) {
+			t.Fatalf("Expected ConfigErrors, got %v", err)
+		}
+		if cfgErrs.Errors[0].Key != "PORTS" || !strings.Contains(cfgErrs.Error(), "item 1: below minimum 1024") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
 func TestLoad_Defaulting(t *testing.T) {
 	// Referring to defaulting.md table
 	type Config struct {
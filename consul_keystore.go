@@ -0,0 +1,244 @@
+package goconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConsulOption configures NewConsulKeyStore.
+type ConsulOption func(*consulSettings)
+
+type consulSettings struct {
+	client     *http.Client
+	token      string
+	datacenter string
+	keyMapping KeyMapper
+	waitTime   time.Duration
+	logger     *slog.Logger
+}
+
+// WithConsulHTTPClient sets the HTTP client, for example to configure TLS. The default is http.DefaultClient.
+// Watch holds requests open for the wait time, so the client's timeout must be longer than that.
+func WithConsulHTTPClient(client *http.Client) ConsulOption {
+	return func(s *consulSettings) { s.client = client }
+}
+
+// WithConsulToken sets the ACL token. The default is the CONSUL_HTTP_TOKEN environment variable.
+func WithConsulToken(token string) ConsulOption {
+	return func(s *consulSettings) { s.token = token }
+}
+
+// WithConsulDatacenter reads from the given datacenter rather than the agent's own.
+func WithConsulDatacenter(datacenter string) ConsulOption {
+	return func(s *consulSettings) { s.datacenter = datacenter }
+}
+
+// WithConsulKeyMapping sets how the Consul key, relative to the prefix, is mapped to a configuration key.
+// The default is EnvStyleKeys, so database/host is read as DATABASE_HOST. A nil mapper uses the relative key as is.
+func WithConsulKeyMapping(mapping KeyMapper) ConsulOption {
+	return func(s *consulSettings) { s.keyMapping = mapping }
+}
+
+// WithConsulWaitTime sets how long Watch asks Consul to hold each blocking query open. The default is 5 minutes.
+func WithConsulWaitTime(wait time.Duration) ConsulOption {
+	return func(s *consulSettings) { s.waitTime = wait }
+}
+
+// WithConsulLogger logs the errors that Watch retries. By default they are not logged.
+func WithConsulLogger(logger *slog.Logger) ConsulOption {
+	return func(s *consulSettings) { s.logger = logger }
+}
+
+// ConsulKeyStore reads keys from a prefix in the Consul KV store. Use its Lookup method as the KeyStore.
+// It is safe for concurrent use.
+type ConsulKeyStore struct {
+	address  string
+	prefix   string
+	settings consulSettings
+
+	// retryDelay is the first delay before Watch retries a failed request
+	retryDelay time.Duration
+}
+
+// consulSnapshot is the content of the prefix at a Consul index.
+type consulSnapshot struct {
+	values map[string]string
+	index  uint64
+}
+
+type consulCacheKey struct {
+	store *ConsulKeyStore
+}
+
+// NewConsulKeyStore returns a store for the keys under prefix on the Consul agent at address, such as
+// http://localhost:8500. If address is empty the CONSUL_HTTP_ADDR environment variable is used, defaulting to
+// http://127.0.0.1:8500.
+//
+// The whole prefix is listed in one request, once per Load, using the context passed to the KeyStore. Keys are
+// named relative to the prefix and mapped with EnvStyleKeys, so myapp/database/host under the prefix myapp/ is
+// read as DATABASE_HOST. Folders are ignored. Error responses are returned as an *HTTPStatusError.
+//
+//	consul := goconfig.NewConsulKeyStore("", "myapp/", goconfig.WithConsulDatacenter("eu-west"))
+//	err := goconfig.Load(ctx, &cfg, goconfig.WithKeyStore(consul.Lookup))
+func NewConsulKeyStore(address, prefix string, options ...ConsulOption) *ConsulKeyStore {
+	if address == "" {
+		address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if address == "" {
+		address = "http://127.0.0.1:8500"
+	}
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	settings := consulSettings{
+		client:     http.DefaultClient,
+		token:      os.Getenv("CONSUL_HTTP_TOKEN"),
+		keyMapping: EnvStyleKeys,
+		waitTime:   5 * time.Minute,
+	}
+	for _, opt := range options {
+		opt(&settings)
+	}
+	return &ConsulKeyStore{
+		address:    strings.TrimSuffix(address, "/"),
+		prefix:     strings.TrimPrefix(prefix, "/"),
+		settings:   settings,
+		retryDelay: time.Second,
+	}
+}
+
+// Lookup implements KeyStore.
+func (c *ConsulKeyStore) Lookup(ctx context.Context, key string) (string, bool, error) {
+	snapshot, err := fetchOncePerLoad(ctx, consulCacheKey{store: c}, func() (any, error) {
+		return c.list(ctx, 0)
+	})
+	if err != nil {
+		return "", false, ConfigError{Key: key, Err: err}
+	}
+	value, ok := snapshot.(*consulSnapshot).values[key]
+	return value, ok, nil
+}
+
+// Watch uses Consul blocking queries to call onChange each time the values under the prefix change, until the
+// context is cancelled. Reload the configuration with Load in onChange. Failed requests are retried with an
+// increasing delay of up to a minute. Watch returns the context's error.
+//
+//	go consul.Watch(ctx, func() { reload(ctx) })
+func (c *ConsulKeyStore) Watch(ctx context.Context, onChange func()) error {
+	var current *consulSnapshot
+	delay := c.retryDelay
+	for {
+		var index uint64
+		if current != nil {
+			index = current.index
+		}
+		next, err := c.list(ctx, index)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if c.settings.logger != nil {
+				c.settings.logger.Warn("consul watch failed, retrying", "prefix", c.prefix, "error", err, "delay", delay)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, time.Minute)
+			continue
+		}
+		delay = c.retryDelay
+
+		// Consul advises starting again if the index goes backwards, as happens when the raft state is restored,
+		// and never blocking on an index of zero, which returns immediately
+		if next.index < index || next.index == 0 {
+			next.index = 1
+		}
+		changed := current != nil && !maps.Equal(current.values, next.values)
+		current = next
+		if changed {
+			onChange()
+		}
+	}
+}
+
+// list reads the prefix. If index is not zero it is a blocking query that returns when the index moves past it
+// or the wait time expires.
+func (c *ConsulKeyStore) list(ctx context.Context, index uint64) (*consulSnapshot, error) {
+	query := url.Values{"recurse": {"true"}}
+	if c.settings.datacenter != "" {
+		query.Set("dc", c.settings.datacenter)
+	}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", fmt.Sprintf("%dms", c.settings.waitTime.Milliseconds()))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.address+"/v1/kv/"+escapePath(c.prefix)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.settings.token != "" {
+		req.Header.Set("X-Consul-Token", c.settings.token)
+	}
+
+	resp, err := c.settings.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("reading consul prefix %s: %w", c.prefix, err)
+	}
+	defer resp.Body.Close()
+
+	snapshot := &consulSnapshot{values: make(map[string]string)}
+	snapshot.index, _ = strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// Nothing under the prefix
+		return snapshot, nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		statusErr := &HTTPStatusError{URL: redactedURL(req.URL), StatusCode: resp.StatusCode}
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if text := strings.TrimSpace(string(message)); text != "" {
+			return nil, fmt.Errorf("%w: %s", statusErr, text)
+		}
+		return nil, statusErr
+	}
+
+	var entries []struct {
+		Key   string
+		Value *string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		// The decoder's messages can quote the response, which holds values
+		return nil, fmt.Errorf("reading consul prefix %s: invalid response from consul", c.prefix)
+	}
+
+	for _, entry := range entries {
+		name := strings.TrimPrefix(strings.TrimPrefix(entry.Key, c.prefix), "/")
+		if entry.Value == nil || name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(*entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("reading consul prefix %s: invalid value encoding for %s", c.prefix, entry.Key)
+		}
+		if c.settings.keyMapping != nil {
+			name = c.settings.keyMapping(name)
+		}
+		snapshot.values[name] = string(value)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,254 @@
+package goconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConsul is a stand-in for the Consul KV endpoints, supporting recursive listing and blocking queries.
+type fakeConsul struct {
+	mu          sync.Mutex
+	kv          map[string]string
+	index       uint64
+	changed     chan struct{}
+	requests    int
+	datacenters []string
+	fail        bool
+}
+
+func newFakeConsul(t *testing.T, kv map[string]string) (*fakeConsul, *httptest.Server) {
+	fake := &fakeConsul{kv: kv, index: 10, changed: make(chan struct{})}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeConsul) set(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.kv[key] = value
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests++
+	f.datacenters = append(f.datacenters, r.URL.Query().Get("dc"))
+	if f.fail {
+		f.mu.Unlock()
+		http.Error(w, "rpc error", http.StatusInternalServerError)
+		return
+	}
+	if r.Header.Get("X-Consul-Token") != "acl-token" {
+		f.mu.Unlock()
+		http.Error(w, "Permission denied", http.StatusForbidden)
+		return
+	}
+	if index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); index >= f.index {
+		wait, _ := time.ParseDuration(r.URL.Query().Get("wait"))
+		changed := f.changed
+		f.mu.Unlock()
+		select {
+		case <-changed:
+		case <-time.After(wait):
+		case <-r.Context().Done():
+			return
+		}
+		f.mu.Lock()
+	}
+	defer f.mu.Unlock()
+
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	type entry struct {
+		Key   string
+		Value *string
+	}
+	entries := []entry{{Key: prefix}}
+	for key, value := range f.kv {
+		if strings.HasPrefix(key, prefix) {
+			encoded := base64.StdEncoding.EncodeToString([]byte(value))
+			entries = append(entries, entry{Key: key, Value: &encoded})
+		}
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.Key, b.Key) })
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	if len(entries) == 1 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+func TestConsulKeyStore(t *testing.T) {
+	ctx := context.Background()
+	kv := func() map[string]string {
+		return map[string]string{
+			"myapp/database/host": "db.internal",
+			"myapp/database/port": "5432",
+			"myapp/api-key":       "abc123",
+			"other/key":           "other",
+		}
+	}
+
+	t.Run("Reads the prefix", func(t *testing.T) {
+		fake, server := newFakeConsul(t, kv())
+		consul := NewConsulKeyStore(server.URL, "myapp/", WithConsulToken("acl-token"), WithConsulDatacenter("eu-west"))
+
+		tests := []struct {
+			key     string
+			want    string
+			present bool
+		}{
+			{"DATABASE_HOST", "db.internal", true},
+			{"DATABASE_PORT", "5432", true},
+			{"API_KEY", "abc123", true},
+			{"KEY", "", false},
+		}
+		for _, tt := range tests {
+			value, ok, err := consul.Lookup(ctx, tt.key)
+			if err != nil || ok != tt.present || value != tt.want {
+				t.Errorf("Lookup(%s) = (%q, %v, %v), want (%q, %v)", tt.key, value, ok, err, tt.want, tt.present)
+			}
+		}
+		if fake.datacenters[0] != "eu-west" {
+			t.Errorf("Expected datacenter eu-west, got %q", fake.datacenters[0])
+		}
+	})
+
+	t.Run("Key mapping", func(t *testing.T) {
+		_, server := newFakeConsul(t, kv())
+		consul := NewConsulKeyStore(server.URL, "myapp", WithConsulToken("acl-token"), WithConsulKeyMapping(nil))
+		if value, ok, err := consul.Lookup(ctx, "database/host"); err != nil || !ok || value != "db.internal" {
+			t.Errorf("Lookup() = (%q, %v, %v)", value, ok, err)
+		}
+	})
+
+	t.Run("Empty prefix", func(t *testing.T) {
+		_, server := newFakeConsul(t, kv())
+		consul := NewConsulKeyStore(server.URL, "missing/", WithConsulToken("acl-token"))
+		if _, ok, err := consul.Lookup(ctx, "DATABASE_HOST"); err != nil || ok {
+			t.Errorf("Lookup() = (%v, %v), want not present", ok, err)
+		}
+	})
+
+	t.Run("One request per Load", func(t *testing.T) {
+		fake, server := newFakeConsul(t, kv())
+		consul := NewConsulKeyStore(server.URL, "myapp/", WithConsulToken("acl-token"))
+
+		type Config struct {
+			Host   string `key:"DATABASE_HOST"`
+			Port   int    `key:"DATABASE_PORT"`
+			APIKey string `key:"API_KEY"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(consul.Lookup)); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Host != "db.internal" || cfg.Port != 5432 || cfg.APIKey != "abc123" {
+			t.Errorf("Got %+v", cfg)
+		}
+		if fake.requests != 1 {
+			t.Errorf("Expected 1 request, got %d", fake.requests)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, server := newFakeConsul(t, kv())
+		consul := NewConsulKeyStore(server.URL, "myapp/", WithConsulToken("wrong"))
+		_, _, err := consul.Lookup(ctx, "API_KEY")
+		var configErr ConfigError
+		if !errors.As(err, &configErr) || configErr.Key != "API_KEY" || !strings.Contains(err.Error(), "403 Forbidden: Permission denied") {
+			t.Errorf("Expected permission error for API_KEY, got %v", err)
+		}
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden || strings.Contains(statusErr.URL, "?") {
+			t.Errorf("Expected HTTPStatusError without query string, got %v", err)
+		}
+	})
+
+	t.Run("Watch notifies changes", func(t *testing.T) {
+		fake, server := newFakeConsul(t, kv())
+		consul := NewConsulKeyStore(server.URL, "myapp/", WithConsulToken("acl-token"))
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		notified := make(chan struct{}, 10)
+		done := make(chan error)
+		go func() { done <- consul.Watch(watchCtx, func() { notified <- struct{}{} }) }()
+
+		waitForRequests := func(n int) {
+			t.Helper()
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				fake.mu.Lock()
+				requests := fake.requests
+				fake.mu.Unlock()
+				if requests >= n {
+					return
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("Timed out waiting for %d requests", n)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		// The initial read and the first blocking query
+		waitForRequests(2)
+		fake.set("other/key", "changed")
+		fake.set("myapp/api-key", "rotated")
+
+		select {
+		case <-notified:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected a notification")
+		}
+		select {
+		case <-notified:
+			t.Error("Expected one notification, as the change outside the prefix does not alter the values")
+		case <-time.After(50 * time.Millisecond):
+		}
+		if value, _, _ := consul.Lookup(ctx, "API_KEY"); value != "rotated" {
+			t.Errorf("Expected rotated value, got %q", value)
+		}
+
+		cancel()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Watch() = %v, want context.Canceled", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Watch did not return")
+		}
+	})
+
+	t.Run("Watch retries failures", func(t *testing.T) {
+		fake, server := newFakeConsul(t, kv())
+		fake.fail = true
+		consul := NewConsulKeyStore(server.URL, "myapp/", WithConsulToken("acl-token"))
+		consul.retryDelay = time.Millisecond
+		watchCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		if err := consul.Watch(watchCtx, func() {}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Watch() = %v, want context.DeadlineExceeded", err)
+		}
+		if fake.requests < 2 {
+			t.Errorf("Expected retries, got %d requests", fake.requests)
+		}
+	})
+}
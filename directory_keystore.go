@@ -0,0 +1,95 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirectoryOption configures NewDirectoryKeyStore.
+type DirectoryOption func(*directorySettings)
+
+type directorySettings struct {
+	fileName func(key string) string
+	files    fileSecretSettings
+}
+
+// WithFileNameMapping sets how a key is mapped to a file name, for example strings.ToLower.
+// The default uses the key as the file name.
+func WithFileNameMapping(mapping func(key string) string) DirectoryOption {
+	return func(s *directorySettings) { s.fileName = mapping }
+}
+
+// WithFileChecks applies the size and permission options of NewFileSecretKeyStore to the files in the directory.
+func WithFileChecks(options ...FileSecretOption) DirectoryOption {
+	return func(s *directorySettings) {
+		for _, opt := range options {
+			opt(&s.files)
+		}
+	}
+}
+
+// NewDirectoryKeyStore returns a KeyStore that reads each key from a file of that name, as used by Kubernetes
+// ConfigMap and Secret volumes. The directories are tried in order and the first to contain the file wins.
+// A single trailing newline is removed from the value.
+//
+// Files are read on every lookup so that updates are seen. Kubernetes updates volumes atomically by swapping the
+// ..data symlink, which is followed each time a file is opened. Hidden files, such as ..data itself, are never read.
+// Missing directories are treated as empty. Failure to read a file that exists is returned as a ConfigError.
+//
+//	store := goconfig.NewDirectoryKeyStore([]string{"/etc/config", "/etc/secrets"},
+//	    goconfig.WithFileNameMapping(strings.ToLower))
+func NewDirectoryKeyStore(directories []string, options ...DirectoryOption) KeyStore {
+	settings := &directorySettings{
+		fileName: func(key string) string { return key },
+		files:    defaultFileSecretSettings(),
+	}
+	for _, opt := range options {
+		opt(settings)
+	}
+	directories = append([]string{}, directories...)
+
+	return func(ctx context.Context, key string) (string, bool, error) {
+		name := settings.fileName(key)
+		if !isVisibleFileName(name) {
+			return "", false, nil
+		}
+
+		for _, dir := range directories {
+			filename := filepath.Join(dir, name)
+			value, err := settings.files.readSecretFile(filename)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return "", false, ConfigError{Key: key, Err: fmt.Errorf("reading file: %w", err)}
+			}
+			return value, true, nil
+		}
+		return "", false, nil
+	}
+}
+
+// NewCredentialsKeyStore returns a KeyStore for systemd credentials, reading files from the directory named by
+// $CREDENTIALS_DIRECTORY. If the variable is not set, as when not running under systemd, no keys are present.
+//
+//	# In the unit file
+//	LoadCredential=db_password:/etc/myapp/db_password
+//
+//	store := goconfig.NewCredentialsKeyStore(goconfig.WithFileNameMapping(strings.ToLower))
+func NewCredentialsKeyStore(options ...DirectoryOption) KeyStore {
+	var directories []string
+	if dir := os.Getenv("CREDENTIALS_DIRECTORY"); dir != "" {
+		directories = append(directories, dir)
+	}
+	return NewDirectoryKeyStore(directories, options...)
+}
+
+// isVisibleFileName rejects names that are hidden or could escape the directory.
+func isVisibleFileName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,125 @@
+package goconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewDirectoryKeyStore(t *testing.T) {
+	ctx := context.Background()
+
+	// Build a volume in the layout Kubernetes uses: each key is a symlink through ..data to a timestamped directory
+	volume := t.TempDir()
+	writeVersion := func(version string, values map[string]string) {
+		dir := filepath.Join(volume, version)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, value := range values {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tmp := filepath.Join(volume, "..data_tmp")
+		if err := os.Symlink(version, tmp); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filepath.Join(volume, "..data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeVersion("..2025_01_01", map[string]string{"DB_HOST": "old-host\n", "LOG_LEVEL": "info"})
+	for _, name := range []string{"DB_HOST", "LOG_LEVEL"} {
+		if err := os.Symlink(filepath.Join("..data", name), filepath.Join(volume, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(volume, ".hidden"), []byte("hidden"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(volume, "SUBDIR"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := t.TempDir()
+	for name, value := range map[string]string{"DB_HOST": "ignored", "db_password": "s3cret\n"} {
+		if err := os.WriteFile(filepath.Join(secrets, name), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	store := NewDirectoryKeyStore([]string{missing, volume, secrets})
+
+	tests := []struct {
+		key         string
+		wantVal     string
+		wantPresent bool
+		wantErr     string
+	}{
+		{key: "DB_HOST", wantVal: "old-host", wantPresent: true},
+		{key: "LOG_LEVEL", wantVal: "info", wantPresent: true},
+		{key: "db_password", wantVal: "s3cret", wantPresent: true},
+		{key: "ABSENT"},
+		{key: ".hidden"},
+		{key: "..data"},
+		{key: "../" + filepath.Base(secrets) + "/db_password"},
+		{key: ""},
+		{key: "SUBDIR", wantErr: "SUBDIR: reading file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			val, present, err := store(ctx, tt.key)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if val != tt.wantVal || present != tt.wantPresent {
+				t.Errorf("Got (%q, %v), want (%q, %v)", val, present, tt.wantVal, tt.wantPresent)
+			}
+		})
+	}
+
+	t.Run("Sees atomic updates", func(t *testing.T) {
+		writeVersion("..2025_01_02", map[string]string{"DB_HOST": "new-host", "LOG_LEVEL": "debug"})
+		if val, _, err := store(ctx, "DB_HOST"); err != nil || val != "new-host" {
+			t.Errorf("Got %q, %v", val, err)
+		}
+	})
+
+	t.Run("File name mapping", func(t *testing.T) {
+		store := NewDirectoryKeyStore([]string{secrets}, WithFileNameMapping(strings.ToLower))
+		if val, present, err := store(ctx, "DB_PASSWORD"); err != nil || !present || val != "s3cret" {
+			t.Errorf("Got (%q, %v, %v)", val, present, err)
+		}
+	})
+
+	t.Run("File checks", func(t *testing.T) {
+		store := NewDirectoryKeyStore([]string{secrets}, WithFileChecks(WithMaxFileSize(3)))
+		if _, _, err := store(ctx, "db_password"); err == nil {
+			t.Error("Expected error for file over the size limit")
+		}
+	})
+
+	t.Run("systemd credentials", func(t *testing.T) {
+		t.Setenv("CREDENTIALS_DIRECTORY", secrets)
+		store := NewCredentialsKeyStore(WithFileNameMapping(strings.ToLower))
+		if val, present, err := store(ctx, "DB_PASSWORD"); err != nil || !present || val != "s3cret" {
+			t.Errorf("Got (%q, %v, %v)", val, present, err)
+		}
+
+		t.Setenv("CREDENTIALS_DIRECTORY", "")
+		store = NewCredentialsKeyStore()
+		if _, present, err := store(ctx, "db_password"); err != nil || present {
+			t.Errorf("Expected no keys without CREDENTIALS_DIRECTORY, got %v, %v", present, err)
+		}
+	})
+}
@@ -35,13 +35,19 @@
 //
 // # Struct Tags
 //
-//   - key: The environment variable name to read from (required)
+//   - key: The environment variable name to read from (required unless WithKeyNaming is used). "-" excludes the field.
+//     A comma separated list gives alternative keys, the first present wins
+//   - deprecated: Comma separated list of old keys that are still read, logging a warning (optional)
 //   - default: The default value to use if the environment variable is not set (optional)
 //   - min: Minimum value for numeric types (optional)
 //   - max: Maximum value for numeric types (optional)
 //   - pattern: Regular expression for string types (optional)
 //   - required: Set to "true" to require the field to not be empty (optional)
 //   - keyRequired: Set to "true" to require the field to be present, though it can be explicitly blank
+//   - sep: Separator for slice and array items, defaulting to "," (optional)
+//   - minItems, maxItems: Bounds on the number of slice items (optional)
+//   - unique: Set to "true" to reject repeated slice items (optional)
+//   - prefix: On a nested struct field, a prefix for every key beneath it (optional)
 //
 // # Supported Types
 //
@@ -53,8 +59,29 @@
 //   - time.Duration (uses Go's duration format: "30s", "1m", "1h", etc.)
 //   - map[string]interface{} using JSON deserialisation
 //   - struct using JSON deserialisation
+//   - types implementing encoding.TextUnmarshaler, json.Unmarshaler or encoding.BinaryUnmarshaler,
+//     such as time.Time, netip.Addr and slog.Level. Registered custom types take precedence. The min, max and
+//     pattern tags check the decoded value if its kind is a number or string, and are rejected otherwise
+//   - slices and arrays of the above, split on the sep tag with each item validated by its own type
 //   - pointers to the above
 //
+// # Key Prefixes
+//
+// The same struct type can be used more than once by giving the nested field a prefix tag.
+// Prefixes compose through each level of nesting. WithKeyPrefix applies a prefix to every key:
+//
+//	type Config struct {
+//	    Primary DatabaseConfig
+//	    Replica DatabaseConfig `prefix:"REPLICA_"`
+//	}
+//
+//	err := goconfig.Load(ctx, &cfg, goconfig.WithKeyPrefix("MYAPP_"))
+//
+// # Derived Keys
+//
+// WithKeyNaming derives keys for fields without a key tag from the field names, so Database.MaxConns
+// becomes DATABASE_MAX_CONNS with SnakeCaseKeys. DotCaseKeys and KebabCaseKeys are also provided.
+//
 // # Custom Validation
 //
 // Use the WithValidator option to add custom validation logic:
@@ -69,6 +96,18 @@
 //	    }),
 //	)
 //
+// # Cross-Field Validation
+//
+// A struct, or nested struct, can implement ConfigValidator or ContextConfigValidator to check rules that involve more
+// than one field. Return FieldError or ConfigError values to attribute errors to keys:
+//
+//	func (p *PoolConfig) Validate() error {
+//	    if p.MaxConns < p.MinConns {
+//	        return goconfig.FieldError("MaxConns", errors.New("must not be less than MIN_CONNS"))
+//	    }
+//	    return nil
+//	}
+//
 // # Custom Parsers
 //
 // Use the WithParser option to provide custom parsing logic for specific fields:
@@ -107,6 +146,7 @@
 //
 //   - ErrMissingConfigKey: returned when a required key is not found in the key store
 //   - ErrMissingValue: returned when a key is found but has a blank value when required="true"
+//   - ErrDeprecatedKeyConflict: returned when a key and its deprecated name are set to different values
 //
 // When multiple configuration errors occur, they are collected into a ConfigErrors
 // type, which implements error and provides an Unwrap method for Go 1.20+ error inspection:
@@ -0,0 +1,223 @@
+package goconfig
+
+import (
+	"strings"
+)
+
+// dotenvParser tokenizes the content of a .env file.
+//
+// The supported syntax is that of the common dotenv tools:
+//   - Blank lines and lines starting with # are ignored
+//   - An optional "export " before the key is ignored
+//   - Unquoted values run to the end of the line. A # preceded by whitespace starts a comment
+//   - Single quoted values are literal and may span lines
+//   - Double quoted values may span lines and support the escapes \n \r \t \\ \" and \$
+type dotenvParser struct {
+	filename string
+	input    string
+	pos      int
+	line     int
+	column   int
+}
+
+// parseDotEnv parses .env content. Later assignments to the same key are returned as separate entries.
+func parseDotEnv(filename string, content string) ([]fileEntry, error) {
+	p := &dotenvParser{
+		filename: filename,
+		input:    strings.ReplaceAll(content, "\r\n", "\n"),
+		line:     1,
+		column:   1,
+	}
+
+	var entries []fileEntry
+	for {
+		p.skipBlankLinesAndComments()
+		if p.atEnd() {
+			return entries, nil
+		}
+		entry, err := p.parseAssignment()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func (p *dotenvParser) atEnd() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *dotenvParser) peek() byte {
+	if p.atEnd() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *dotenvParser) next() byte {
+	c := p.input[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+		p.column = 1
+	} else {
+		p.column++
+	}
+	return c
+}
+
+func (p *dotenvParser) errorf(line, column int, msg string) error {
+	return &SyntaxError{File: p.filename, Line: line, Column: column, Msg: msg}
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) skipToEndOfLine() {
+	for !p.atEnd() && p.peek() != '\n' {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) skipBlankLinesAndComments() {
+	for !p.atEnd() {
+		p.skipSpaces()
+		switch p.peek() {
+		case '#':
+			p.skipToEndOfLine()
+		case '\n':
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+// parseAssignment parses KEY=value, with an optional export prefix, up to and including the end of the line.
+func (p *dotenvParser) parseAssignment() (fileEntry, error) {
+	line := p.line
+	key := p.parseKey()
+	if key == "export" && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		key = p.parseKey()
+	}
+	if key == "" {
+		return fileEntry{}, p.errorf(p.line, p.column, "expected a key")
+	}
+
+	p.skipSpaces()
+	if p.peek() != '=' {
+		return fileEntry{}, p.errorf(p.line, p.column, "expected '=' after key "+key)
+	}
+	p.next()
+	p.skipSpaces()
+
+	var value string
+	var err error
+	switch p.peek() {
+	case '\'':
+		value, err = p.parseSingleQuoted()
+	case '"':
+		value, err = p.parseDoubleQuoted()
+	default:
+		value = p.parseUnquoted()
+	}
+	if err != nil {
+		return fileEntry{}, err
+	}
+
+	// Only a comment may follow the value
+	p.skipSpaces()
+	switch p.peek() {
+	case '#':
+		p.skipToEndOfLine()
+	case '\n', 0:
+	default:
+		return fileEntry{}, p.errorf(p.line, p.column, "unexpected characters after quoted value for "+key)
+	}
+	if !p.atEnd() {
+		p.next()
+	}
+	return fileEntry{Key: key, Value: value, Line: line}, nil
+}
+
+func (p *dotenvParser) parseKey() string {
+	start := p.pos
+	for !p.atEnd() && isEnvKeyChar(p.peek()) {
+		p.next()
+	}
+	return p.input[start:p.pos]
+}
+
+func isEnvKeyChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// parseUnquoted reads to the end of the line or an inline comment, trimming trailing whitespace.
+func (p *dotenvParser) parseUnquoted() string {
+	start := p.pos
+	end := p.pos
+	for !p.atEnd() && p.peek() != '\n' {
+		c := p.peek()
+		if c == '#' && p.pos > start && (p.input[p.pos-1] == ' ' || p.input[p.pos-1] == '\t') {
+			break
+		}
+		p.next()
+		if c != ' ' && c != '\t' {
+			end = p.pos
+		}
+	}
+	return p.input[start:end]
+}
+
+// parseSingleQuoted reads a literal value up to the closing quote.
+func (p *dotenvParser) parseSingleQuoted() (string, error) {
+	line, column := p.line, p.column
+	p.next()
+	start := p.pos
+	for !p.atEnd() && p.peek() != '\'' {
+		p.next()
+	}
+	if p.atEnd() {
+		return "", p.errorf(line, column, "unterminated single quoted value")
+	}
+	value := p.input[start:p.pos]
+	p.next()
+	return value, nil
+}
+
+// parseDoubleQuoted reads a value up to the closing quote, processing escape sequences.
+// Unknown escapes are kept as written so that Windows paths and regular expressions survive.
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+	line, column := p.line, p.column
+	p.next()
+	var value strings.Builder
+	for !p.atEnd() {
+		c := p.next()
+		switch {
+		case c == '"':
+			return value.String(), nil
+		case c == '\\' && !p.atEnd():
+			escaped := p.next()
+			switch escaped {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '\\', '"', '$':
+				value.WriteByte(escaped)
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(escaped)
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", p.errorf(line, column, "unterminated double quoted value")
+}
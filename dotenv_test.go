@@ -0,0 +1,110 @@
+package goconfig
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []fileEntry
+		wantErr *SyntaxError
+	}{
+		{
+			name:  "simple assignments",
+			input: "A=1\nB = two \n\nC=",
+			want:  []fileEntry{{"A", "1", 1}, {"B", "two", 2}, {"C", "", 4}},
+		},
+		{
+			name:  "comments",
+			input: "# comment\n  # indented comment\nA=1 # inline\nB=a#b\nC=#notcomment\nD=x\t# tab comment",
+			want:  []fileEntry{{"A", "1", 3}, {"B", "a#b", 4}, {"C", "#notcomment", 5}, {"D", "x", 6}},
+		},
+		{
+			name:  "export prefix",
+			input: "export A=1\nexport\tB=2\nexport=3",
+			want:  []fileEntry{{"A", "1", 1}, {"B", "2", 2}, {"export", "3", 3}},
+		},
+		{
+			name:  "single quotes are literal",
+			input: `A='a \n $B "c" # not comment' # comment`,
+			want:  []fileEntry{{"A", `a \n $B "c" # not comment`, 1}},
+		},
+		{
+			name:  "double quote escapes",
+			input: `A="line1\nline2\ttab \"quoted\" \\ \$HOME \d"`,
+			want:  []fileEntry{{"A", "line1\nline2\ttab \"quoted\" \\ $HOME \\d", 1}},
+		},
+		{
+			name:  "multiline values",
+			input: "KEY=\"-----BEGIN KEY-----\nabc\n-----END KEY-----\"\nSINGLE='a\nb'\nNEXT=1",
+			want: []fileEntry{
+				{"KEY", "-----BEGIN KEY-----\nabc\n-----END KEY-----", 1},
+				{"SINGLE", "a\nb", 4},
+				{"NEXT", "1", 6},
+			},
+		},
+		{
+			name:  "windows line endings",
+			input: "A=1\r\nB=\"x\"\r\n",
+			want:  []fileEntry{{"A", "1", 1}, {"B", "x", 2}},
+		},
+		{
+			name:  "repeated keys are kept",
+			input: "A=1\nA=2",
+			want:  []fileEntry{{"A", "1", 1}, {"A", "2", 2}},
+		},
+		{
+			name:    "missing equals",
+			input:   "A=1\nJUST_A_KEY\n",
+			wantErr: &SyntaxError{File: "test.env", Line: 2, Column: 11, Msg: "expected '=' after key JUST_A_KEY"},
+		},
+		{
+			name:    "invalid key",
+			input:   "A B=1",
+			wantErr: &SyntaxError{File: "test.env", Line: 1, Column: 3, Msg: "expected '=' after key A"},
+		},
+		{
+			name:    "no key",
+			input:   "=1",
+			wantErr: &SyntaxError{File: "test.env", Line: 1, Column: 1, Msg: "expected a key"},
+		},
+		{
+			name:    "unterminated double quote",
+			input:   "A=1\nB=\"abc\n\ndef",
+			wantErr: &SyntaxError{File: "test.env", Line: 2, Column: 3, Msg: "unterminated double quoted value"},
+		},
+		{
+			name:    "unterminated single quote",
+			input:   "B='abc",
+			wantErr: &SyntaxError{File: "test.env", Line: 1, Column: 3, Msg: "unterminated single quoted value"},
+		},
+		{
+			name:    "text after quoted value",
+			input:   `A="abc"def`,
+			wantErr: &SyntaxError{File: "test.env", Line: 1, Column: 8, Msg: "unexpected characters after quoted value for A"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDotEnv("test.env", tt.input)
+			if tt.wantErr != nil {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) || *syntaxErr != *tt.wantErr {
+					t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,76 +1,159 @@
 package goconfig
 
 import (
-	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
-	"strings"
 )
 
-// NewEnvFileKeyStore returns a KeyStore that reads values from a list of environment files.
-// If no filenames are provided, it defaults to ".env".
-// Files are processed in the order they are provided. If multiple files contain the same key,
-// the first one encountered wins.
-func NewEnvFileKeyStore(filenames ...string) KeyStore {
-	if len(filenames) == 0 {
-		filenames = []string{".env"}
+// EnvFileOption configures LoadEnvFileKeyStore.
+type EnvFileOption func(*envFileSettings)
+
+type envFileSettings struct {
+	files []envFileSpec
+	// logger receives duplicate key warnings. If nil then duplicates are not reported
+	logger *slog.Logger
+}
+
+type envFileSpec struct {
+	filename string
+	optional bool
+}
+
+// WithEnvFile adds a file that must exist. Files are read in the order they are added.
+func WithEnvFile(filename string) EnvFileOption {
+	return func(s *envFileSettings) {
+		s.files = append(s.files, envFileSpec{filename: filename})
 	}
+}
 
-	// Pre-load all files into a map
-	values := make(map[string]string)
-	for _, filename := range filenames {
-		fileValues, err := readEnvFile(filename)
-		if err != nil {
-			// If a file doesn't exist or can't be read, we just skip it as per typical .env behavior
-			continue
-		}
-		for k, v := range fileValues {
-			if _, exists := values[k]; !exists {
-				values[k] = v
-			}
-		}
+// WithOptionalEnvFile adds a file that is skipped if it does not exist. Other errors, such as permission denied,
+// are still reported.
+func WithOptionalEnvFile(filename string) EnvFileOption {
+	return func(s *envFileSettings) {
+		s.files = append(s.files, envFileSpec{filename: filename, optional: true})
 	}
+}
 
-	return func(ctx context.Context, key string) (string, bool, error) {
-		val, ok := values[key]
-		return val, ok, nil
+// WithDuplicateKeyWarnings logs a warning for each key that is set more than once, either within a file or
+// across files. The warning gives the key and where it was set, but not the values.
+func WithDuplicateKeyWarnings(logger *slog.Logger) EnvFileOption {
+	return func(s *envFileSettings) {
+		s.logger = logger
 	}
 }
 
-func readEnvFile(filename string) (map[string]string, error) {
-	file, err := os.Open(filename)
+// LoadEnvFileKeyStore reads the given environment files and returns a KeyStore for their values.
+// If no files are given, it reads ".env" if it exists. If multiple files contain the same key, the first one
+// encountered wins. Within a file, the last assignment to a key wins.
+//
+// Unlike NewEnvFileKeyStore, a missing required file is an error, and errors are returned immediately
+// rather than on first use.
+//
+//	store, err := goconfig.LoadEnvFileKeyStore(
+//	    goconfig.WithEnvFile("config/base.env"),
+//	    goconfig.WithOptionalEnvFile(".env.local"),
+//	    goconfig.WithDuplicateKeyWarnings(logger),
+//	)
+func LoadEnvFileKeyStore(options ...EnvFileOption) (KeyStore, error) {
+	settings := &envFileSettings{}
+	for _, opt := range options {
+		opt(settings)
+	}
+	if len(settings.files) == 0 {
+		settings.files = []envFileSpec{{filename: ".env", optional: true}}
+	}
+
+	values, err := settings.load()
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	return mapKeyStore(values), nil
+}
 
-	values := make(map[string]string)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+// NewEnvFileKeyStore returns a KeyStore that reads values from a list of environment files.
+// If no filenames are provided, it defaults to ".env".
+// Files are processed in the order they are provided. If multiple files contain the same key,
+// the first one encountered wins. Within a file, the last assignment to a key wins.
+//
+// Files that do not exist are skipped as per typical .env behaviour. A file that cannot be read or is malformed
+// causes every lookup to return the error, such as a *SyntaxError giving the file and line, so that Load fails
+// rather than silently ignoring the problem. Use LoadEnvFileKeyStore to require files or to see errors immediately.
+func NewEnvFileKeyStore(filenames ...string) KeyStore {
+	options := make([]EnvFileOption, 0, len(filenames))
+	for _, filename := range filenames {
+		options = append(options, WithOptionalEnvFile(filename))
+	}
+
+	store, err := LoadEnvFileKeyStore(options...)
+	if err != nil {
+		return func(ctx context.Context, key string) (string, bool, error) {
+			return "", false, err
 		}
+	}
+	return store
+}
+
+// envValueSource records where a value was set, for duplicate key warnings.
+type envValueSource struct {
+	filename string
+	line     int
+}
 
-		// Split by first '='
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
+// load reads all files into a map, applying the precedence rules.
+func (s *envFileSettings) load() (map[string]string, error) {
+	values := make(map[string]string)
+	sources := make(map[string]envValueSource)
+
+	for _, file := range s.files {
+		entries, err := readEnvFileEntries(file.filename)
+		if file.optional && errors.Is(err, fs.ErrNotExist) {
 			continue
 		}
+		if err != nil {
+			var syntaxErr *SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("reading env file: %w", err)
+		}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		// Remove quotes if present
-		if len(value) >= 2 {
-			if (value[0] == '"' && value[len(value)-1] == '"') || (value[0] == '\'' && value[len(value)-1] == '\'') {
-				value = value[1 : len(value)-1]
+		for _, entry := range entries {
+			source := envValueSource{filename: file.filename, line: entry.Line}
+			if previous, exists := sources[entry.Key]; exists {
+				s.warnDuplicate(entry.Key, source, previous)
+				if previous.filename != file.filename {
+					// An earlier file takes precedence
+					continue
+				}
 			}
+			sources[entry.Key] = source
+			values[entry.Key] = entry.Value
 		}
+	}
+	return values, nil
+}
 
-		values[key] = value
+func (s *envFileSettings) warnDuplicate(key string, source, previous envValueSource) {
+	if s.logger == nil {
+		return
 	}
+	s.logger.Warn("duplicate key in env file",
+		"key", key,
+		"file", source.filename,
+		"line", source.line,
+		"previous_file", previous.filename,
+		"previous_line", previous.line)
+}
 
-	return values, scanner.Err()
+// readEnvFileEntries reads and parses a .env file, keeping the order and line numbers of the assignments.
+func readEnvFileEntries(filename string) ([]fileEntry, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return parseDotEnv(filename, string(content))
 }
@@ -1,8 +1,13 @@
 package goconfig
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -90,4 +95,94 @@ SINGLE_QUOTED='single quoted'
 			t.Error("Expected ok=false for nonexistent file")
 		}
 	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "bad.env")
+		os.WriteFile(filename, []byte("GOOD=1\nBAD\n"), 0644)
+
+		store := NewEnvFileKeyStore(filename)
+		_, _, err := store(context.Background(), "GOOD")
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.File != filename || syntaxErr.Line != 2 {
+			t.Errorf("Expected syntax error on line 2 of %s, got %v", filename, err)
+		}
+	})
+}
+
+func TestLoadEnvFileKeyStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	base := writeFile("base.env", "A=base\nB=base\nB=base again\n")
+	local := writeFile("local.env", "A=local\nC=local\n")
+	bad := writeFile("bad.env", "OK=1\n\"oops\n")
+	missing := filepath.Join(dir, "missing.env")
+
+	t.Run("Required and optional files", func(t *testing.T) {
+		store, err := LoadEnvFileKeyStore(WithEnvFile(base), WithOptionalEnvFile(missing), WithOptionalEnvFile(local))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for key, want := range map[string]string{"A": "base", "B": "base again", "C": "local"} {
+			if val, ok, err := store(ctx, key); !ok || err != nil || val != want {
+				t.Errorf("%s: got (%q, %v, %v), want %q", key, val, ok, err, want)
+			}
+		}
+	})
+
+	t.Run("Missing required file", func(t *testing.T) {
+		_, err := LoadEnvFileKeyStore(WithEnvFile(missing))
+		if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), missing) {
+			t.Errorf("Expected not exist error naming the file, got %v", err)
+		}
+	})
+
+	t.Run("Unreadable optional file", func(t *testing.T) {
+		_, err := LoadEnvFileKeyStore(WithOptionalEnvFile(dir))
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected read error, got %v", err)
+		}
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		_, err := LoadEnvFileKeyStore(WithOptionalEnvFile(bad))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.File != bad || syntaxErr.Line != 2 {
+			t.Errorf("Expected syntax error on line 2, got %v", err)
+		}
+	})
+
+	t.Run("Unreadable file fails Load through NewEnvFileKeyStore", func(t *testing.T) {
+		type Config struct {
+			A string `key:"A"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(NewEnvFileKeyStore(dir))); err == nil {
+			t.Error("Expected Load to fail")
+		}
+	})
+
+	t.Run("Duplicate key warnings", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		if _, err := LoadEnvFileKeyStore(WithEnvFile(base), WithEnvFile(local), WithDuplicateKeyWarnings(logger)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		output := buf.String()
+		if strings.Count(output, "duplicate key in env file") != 2 {
+			t.Errorf("Expected two warnings, got %s", output)
+		}
+		if !strings.Contains(output, "key=B") || !strings.Contains(output, "key=A") {
+			t.Errorf("Expected warnings for A and B, got %s", output)
+		}
+		if strings.Contains(output, "base again") || strings.Contains(output, "=local") {
+			t.Errorf("Warnings must not contain values, got %s", output)
+		}
+	})
 }
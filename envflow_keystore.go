@@ -0,0 +1,129 @@
+package goconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvFlowOption configures NewEnvFlowKeyStore.
+type EnvFlowOption func(*envFlowSettings)
+
+type envFlowSettings struct {
+	environment    string
+	hasEnvironment bool
+	directory      string
+	searchUpward   bool
+	envFileOptions []EnvFileOption
+}
+
+// WithEnvironment sets the environment name, such as "production" or "test". The default is the value of APP_ENV.
+func WithEnvironment(name string) EnvFlowOption {
+	return func(s *envFlowSettings) {
+		s.environment = name
+		s.hasEnvironment = true
+	}
+}
+
+// WithEnvDirectory sets the directory to read files from. The default is the working directory.
+func WithEnvDirectory(dir string) EnvFlowOption {
+	return func(s *envFlowSettings) { s.directory = dir }
+}
+
+// WithUpwardSearch also reads files from parent directories up to and including the module root, the nearest
+// directory containing go.mod. Files in nearer directories take precedence. If there is no go.mod above the
+// directory then only the directory itself is read.
+func WithUpwardSearch() EnvFlowOption {
+	return func(s *envFlowSettings) { s.searchUpward = true }
+}
+
+// WithEnvFileOptions passes options such as WithDuplicateKeyWarnings through to LoadEnvFileKeyStore.
+func WithEnvFileOptions(options ...EnvFileOption) EnvFlowOption {
+	return func(s *envFlowSettings) { s.envFileOptions = append(s.envFileOptions, options...) }
+}
+
+// NewEnvFlowKeyStore returns a KeyStore that reads the dotenv-flow set of files for an environment. In order of
+// precedence, highest first:
+//
+//	.env.<environment>.local  machine specific overrides for the environment
+//	.env.local                machine specific overrides, not read when the environment is "test"
+//	.env.<environment>        shared settings for the environment
+//	.env                      shared defaults
+//
+// If no environment is set then only .env.local and .env are read. All files are optional. Errors reading or
+// parsing a file are returned immediately.
+//
+//	files, err := goconfig.NewEnvFlowKeyStore(goconfig.WithUpwardSearch())
+//	store := goconfig.CompositeStore(goconfig.EnvironmentKeyStore, files)
+func NewEnvFlowKeyStore(options ...EnvFlowOption) (KeyStore, error) {
+	settings := &envFlowSettings{}
+	for _, opt := range options {
+		opt(settings)
+	}
+	if !settings.hasEnvironment {
+		settings.environment = os.Getenv("APP_ENV")
+	}
+	if strings.ContainsAny(settings.environment, `/\`) || settings.environment == "." || settings.environment == ".." {
+		return nil, fmt.Errorf("invalid environment name %q", settings.environment)
+	}
+
+	directories, err := settings.directories()
+	if err != nil {
+		return nil, err
+	}
+
+	fileOptions := append([]EnvFileOption{}, settings.envFileOptions...)
+	for _, dir := range directories {
+		for _, name := range settings.fileNames() {
+			fileOptions = append(fileOptions, WithOptionalEnvFile(filepath.Join(dir, name)))
+		}
+	}
+	return LoadEnvFileKeyStore(fileOptions...)
+}
+
+// fileNames returns the files to read in each directory, highest precedence first.
+func (s *envFlowSettings) fileNames() []string {
+	env := s.environment
+	switch {
+	case env == "":
+		return []string{".env.local", ".env"}
+	case env == "test":
+		// Tests should give the same results on every machine
+		return []string{".env.test.local", ".env.test", ".env"}
+	default:
+		return []string{".env." + env + ".local", ".env.local", ".env." + env, ".env"}
+	}
+}
+
+// directories returns the directories to read, nearest first.
+func (s *envFlowSettings) directories() ([]string, error) {
+	start := s.directory
+	if start == "" {
+		var err error
+		if start, err = os.Getwd(); err != nil {
+			return nil, err
+		}
+	}
+	start, err := filepath.Abs(start)
+	if err != nil {
+		return nil, err
+	}
+	if !s.searchUpward {
+		return []string{start}, nil
+	}
+
+	var directories []string
+	for dir := start; ; {
+		directories = append(directories, dir)
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return directories, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// No module root, so do not read files from unrelated directories
+			return []string{start}, nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,141 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewEnvFlowKeyStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	service := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(service, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile := func(dir, name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// Each key is set in every file of lower precedence than the one named by its value
+	writeFile(service, ".env", "BASE=.env\nENV=.env\nLOCAL=.env\nENV_LOCAL=.env")
+	writeFile(service, ".env.production", "ENV=.env.production\nLOCAL=.env.production\nENV_LOCAL=.env.production")
+	writeFile(service, ".env.local", "LOCAL=.env.local\nENV_LOCAL=.env.local")
+	writeFile(service, ".env.production.local", "ENV_LOCAL=.env.production.local")
+	writeFile(service, ".env.test", "ENV=.env.test\nLOCAL=.env.test")
+	writeFile(service, ".env.test.local", "ENV_LOCAL=.env.test.local")
+	writeFile(root, "go.mod", "module example.com/monorepo\n")
+	writeFile(root, ".env", "SHARED=root\nBASE=root")
+
+	lookup := func(t *testing.T, store KeyStore, key string) string {
+		t.Helper()
+		val, ok, err := store(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", key, err)
+		}
+		if !ok {
+			return "<unset>"
+		}
+		return val
+	}
+
+	tests := []struct {
+		name    string
+		options []EnvFlowOption
+		want    map[string]string
+	}{
+		{
+			name:    "production",
+			options: []EnvFlowOption{WithEnvironment("production")},
+			want: map[string]string{
+				"BASE":      ".env",
+				"ENV":       ".env.production",
+				"LOCAL":     ".env.local",
+				"ENV_LOCAL": ".env.production.local",
+				"SHARED":    "<unset>",
+			},
+		},
+		{
+			name:    "no environment",
+			options: []EnvFlowOption{WithEnvironment("")},
+			want: map[string]string{
+				"ENV":       ".env",
+				"LOCAL":     ".env.local",
+				"ENV_LOCAL": ".env.local",
+			},
+		},
+		{
+			name:    "test skips .env.local",
+			options: []EnvFlowOption{WithEnvironment("test")},
+			want: map[string]string{
+				"ENV":       ".env.test",
+				"LOCAL":     ".env.test",
+				"ENV_LOCAL": ".env.test.local",
+			},
+		},
+		{
+			name:    "upward search to module root",
+			options: []EnvFlowOption{WithEnvironment("production"), WithUpwardSearch()},
+			want: map[string]string{
+				"BASE":   ".env",
+				"SHARED": "root",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewEnvFlowKeyStore(append(tt.options, WithEnvDirectory(service))...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for key, want := range tt.want {
+				if got := lookup(t, store, key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("environment from APP_ENV", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+		store, err := NewEnvFlowKeyStore(WithEnvDirectory(service))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := lookup(t, store, "ENV"); got != ".env.production" {
+			t.Errorf("ENV = %q", got)
+		}
+	})
+
+	t.Run("upward search without module root", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewEnvFlowKeyStore(WithEnvDirectory(dir), WithUpwardSearch())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := lookup(t, store, "SHARED"); got != "<unset>" {
+			t.Errorf("SHARED = %q", got)
+		}
+	})
+
+	t.Run("invalid environment name", func(t *testing.T) {
+		if _, err := NewEnvFlowKeyStore(WithEnvironment("../secrets")); err == nil {
+			t.Error("Expected error for environment containing a path")
+		}
+	})
+
+	t.Run("syntax errors are returned", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(dir, ".env.staging", "BROKEN")
+		_, err := NewEnvFlowKeyStore(WithEnvDirectory(dir), WithEnvironment("staging"))
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Expected SyntaxError, got %v", err)
+		}
+	})
+}
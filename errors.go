@@ -2,13 +2,17 @@ package goconfig
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"strings"
 )
 
 var (
 	ErrMissingConfigKey = errors.New("no configuration found for this key")
 	ErrMissingValue     = errors.New("missing or blank value for this key")
+	// ErrDeprecatedKeyConflict is reported when both a key and its deprecated name are set to different values.
+	ErrDeprecatedKeyConflict = errors.New("value conflicts with deprecated key")
 )
 
 // ConfigErrors collects multiple runtime configuration errors.
@@ -24,6 +28,37 @@ type ConfigError struct {
 	Err error  // The underlying error
 }
 
+// Error implements the error interface so that a ConfigError can be returned from a Validate method
+// to attribute an error to a specific key.
+func (e ConfigError) Error() string {
+	return e.Key + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError attributes an error returned from a Validate method to a field of the struct being validated, given its
+// Go field name. The error is reported against the key that was used for that field, including any prefix.
+func FieldError(field string, err error) error {
+	return &fieldError{Field: field, Err: err}
+}
+
+// fieldError is an error attributed to a field by name.
+type fieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.Err
+}
+
 // Error implements the error interface.
 // It formats all collected errors as: "KEY1: error1; KEY2: error2"
 func (ce *ConfigErrors) Error() string {
@@ -123,3 +158,30 @@ func getLogSettings(opts ...ErrorLogOption) *logSettings {
 
 	return settings
 }
+
+// SyntaxError reports a malformed configuration file. Line and Column are 1-based, and Column is zero if unknown.
+// The message describes the problem without repeating the file's content, which may be secret.
+type SyntaxError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// HTTPStatusError reports an unsuccessful HTTP response from a remote configuration source.
+// The URL does not include the query string, which may hold credentials.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
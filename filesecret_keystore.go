@@ -0,0 +1,134 @@
+package goconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FileSecretOption configures NewFileSecretKeyStore.
+type FileSecretOption func(*fileSecretSettings)
+
+type fileSecretSettings struct {
+	suffix         string
+	maxSize        int64
+	forbiddenPerms os.FileMode
+}
+
+// WithFileSuffix changes the suffix used to find the file key. The default is "_FILE".
+func WithFileSuffix(suffix string) FileSecretOption {
+	return func(s *fileSecretSettings) { s.suffix = suffix }
+}
+
+// WithMaxFileSize limits the size of secret files. The default is 64 KiB.
+func WithMaxFileSize(bytes int64) FileSecretOption {
+	return func(s *fileSecretSettings) { s.maxSize = bytes }
+}
+
+// WithForbiddenPermissions rejects secret files that have any of the given permission bits set.
+// The default rejects world-writable files. Use 0o077 to require that only the owner can access the file,
+// though note that Docker and Kubernetes mount secrets world-readable by default.
+func WithForbiddenPermissions(perms os.FileMode) FileSecretOption {
+	return func(s *fileSecretSettings) { s.forbiddenPerms = perms }
+}
+
+// NewFileSecretKeyStore wraps a KeyStore to support the Docker secrets convention. If KEY is not present but
+// KEY_FILE is, then the value is read from the file named by KEY_FILE, removing a single trailing newline.
+// Failure to read the file is returned as a ConfigError for KEY rather than treating the key as absent.
+//
+//	store := goconfig.NewFileSecretKeyStore(goconfig.EnvironmentKeyStore)
+//	// DB_PASSWORD_FILE=/run/secrets/db_password satisfies key:"DB_PASSWORD"
+func NewFileSecretKeyStore(store KeyStore, options ...FileSecretOption) KeyStore {
+	settings := defaultFileSecretSettings()
+	for _, opt := range options {
+		opt(&settings)
+	}
+
+	return func(ctx context.Context, key string) (string, bool, error) {
+		value, present, err := store(ctx, key)
+		if present || err != nil {
+			return value, present, err
+		}
+
+		fileKey := key + settings.suffix
+		filename, present, err := store(ctx, fileKey)
+		if !present || err != nil {
+			return "", false, err
+		}
+
+		value, err = settings.readSecretFile(filename)
+		if err != nil {
+			return "", false, ConfigError{Key: key, Err: fmt.Errorf("reading file from %s: %w", fileKey, err)}
+		}
+		return value, true, nil
+	}
+}
+
+func defaultFileSecretSettings() fileSecretSettings {
+	return fileSecretSettings{
+		suffix:         "_FILE",
+		maxSize:        64 * 1024,
+		forbiddenPerms: 0o002,
+	}
+}
+
+// secretFileError rejects a secret file that will not change by trying again, such as one that is too large.
+type secretFileError string
+
+func (e secretFileError) Error() string {
+	return string(e)
+}
+
+// readSecretFile reads the file after checking its type, permissions and size.
+func (s *fileSecretSettings) readSecretFile(filename string) (string, error) {
+	if filename == "" {
+		return "", secretFileError("file name is blank")
+	}
+
+	// Check before opening, because opening a FIFO blocks until something writes to it
+	info, err := os.Stat(filename)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", secretFileError(fmt.Sprintf("%s is not a regular file", filename))
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	// Check the file that was opened, in case the name was replaced after the check
+	info, err = file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", secretFileError(fmt.Sprintf("%s is not a regular file", filename))
+	}
+	if perms := info.Mode().Perm() & s.forbiddenPerms; perms != 0 {
+		return "", secretFileError(fmt.Sprintf("%s has forbidden permissions %#o", filename, perms))
+	}
+	if info.Size() > s.maxSize {
+		return "", secretFileError(fmt.Sprintf("%s is larger than %d bytes", filename, s.maxSize))
+	}
+
+	// Limit the read in case the file grows after the check
+	content, err := io.ReadAll(io.LimitReader(file, s.maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(content)) > s.maxSize {
+		return "", secretFileError(fmt.Sprintf("%s is larger than %d bytes", filename, s.maxSize))
+	}
+
+	value := string(content)
+	if strings.HasSuffix(value, "\r\n") {
+		return strings.TrimSuffix(value, "\r\n"), nil
+	}
+	return strings.TrimSuffix(value, "\n"), nil
+}
@@ -0,0 +1,117 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewFileSecretKeyStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	writeSecret := func(name, content string, perm os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), perm); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if err := os.Chmod(path, perm); err != nil {
+			t.Fatalf("Failed to chmod %s: %v", name, err)
+		}
+		return path
+	}
+
+	values := map[string]string{
+		"DIRECT":             "direct",
+		"DIRECT_FILE":        writeSecret("direct", "from file", 0o600),
+		"PASSWORD_FILE":      writeSecret("password", "s3cret\n", 0o444),
+		"CRLF_FILE":          writeSecret("crlf", "line\r\n", 0o600),
+		"MULTILINE_FILE":     writeSecret("multiline", "a\nb\n\n", 0o600),
+		"MISSING_FILE":       filepath.Join(dir, "missing"),
+		"WRITABLE_FILE":      writeSecret("writable", "x", 0o666),
+		"LARGE_FILE":         writeSecret("large", strings.Repeat("x", 100), 0o600),
+		"DIRECTORY_FILE":     dir,
+		"BLANK_FILE":         "",
+		"STORE_FAILURE_FILE": "unused",
+	}
+	storeErr := errors.New("store failure")
+	baseStore := func(ctx context.Context, key string) (string, bool, error) {
+		if key == "STORE_FAILURE_FILE" {
+			return "", false, storeErr
+		}
+		val, ok := values[key]
+		return val, ok, nil
+	}
+
+	store := NewFileSecretKeyStore(baseStore, WithMaxFileSize(50))
+
+	tests := []struct {
+		key         string
+		wantVal     string
+		wantPresent bool
+		wantErr     string
+	}{
+		{key: "DIRECT", wantVal: "direct", wantPresent: true},
+		{key: "PASSWORD", wantVal: "s3cret", wantPresent: true},
+		{key: "CRLF", wantVal: "line", wantPresent: true},
+		{key: "MULTILINE", wantVal: "a\nb\n", wantPresent: true},
+		{key: "ABSENT"},
+		{key: "MISSING", wantErr: "MISSING: reading file from MISSING_FILE"},
+		{key: "WRITABLE", wantErr: "forbidden permissions 02"},
+		{key: "LARGE", wantErr: "larger than 50 bytes"},
+		{key: "DIRECTORY", wantErr: "not a regular file"},
+		{key: "BLANK", wantErr: "file name is blank"},
+		{key: "STORE_FAILURE", wantErr: "store failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			val, present, err := store(ctx, tt.key)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if val != tt.wantVal || present != tt.wantPresent {
+				t.Errorf("Got (%q, %v), want (%q, %v)", val, present, tt.wantVal, tt.wantPresent)
+			}
+		})
+	}
+
+	t.Run("Owner only permissions", func(t *testing.T) {
+		strict := NewFileSecretKeyStore(baseStore, WithForbiddenPermissions(0o077))
+		if _, _, err := strict(ctx, "PASSWORD"); err == nil {
+			t.Error("Expected error for world readable file")
+		}
+		if val, _, err := strict(ctx, "CRLF"); err != nil || val != "line" {
+			t.Errorf("Got %q, %v", val, err)
+		}
+	})
+
+	t.Run("Custom suffix", func(t *testing.T) {
+		values["TOKEN__PATH"] = writeSecret("token", "abc", 0o600)
+		store := NewFileSecretKeyStore(baseStore, WithFileSuffix("__PATH"))
+		if val, present, err := store(ctx, "TOKEN"); err != nil || !present || val != "abc" {
+			t.Errorf("Got (%q, %v, %v)", val, present, err)
+		}
+	})
+
+	t.Run("Read failure is attributed to the key by Load", func(t *testing.T) {
+		type Config struct {
+			Password string `key:"MISSING"`
+		}
+		var cfg Config
+		err := Load(ctx, &cfg, WithKeyStore(store))
+		var configErr ConfigError
+		if !errors.As(err, &configErr) || configErr.Key != "MISSING" {
+			t.Errorf("Expected ConfigError for MISSING, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package goconfig
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNewFileSecretKeyStore_FIFO(t *testing.T) {
+	fifo := filepath.Join(t.TempDir(), "fifo")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Skipf("cannot create FIFO: %v", err)
+	}
+	store := NewFileSecretKeyStore(func(ctx context.Context, key string) (string, bool, error) {
+		return fifo, key == "PIPE_FILE", nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := store(context.Background(), "PIPE")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "not a regular file") {
+			t.Errorf("Expected error for FIFO, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Reading a FIFO blocked")
+	}
+}
@@ -0,0 +1,89 @@
+package goconfig
+
+import (
+	"context"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// fileEntry is a single name and value read from a configuration file.
+type fileEntry struct {
+	Key   string
+	Value string
+	Line  int
+}
+
+// KeyMapper maps a name read from a configuration file, such as db.max.conns, to the key that Load asks for.
+type KeyMapper func(name string) string
+
+// EnvStyleKeys maps names to environment variable style keys. Dots, dashes and other punctuation become
+// underscores and camel case is split into words, so db.max.conns and db.maxConns both become DB_MAX_CONNS.
+func EnvStyleKeys(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.ToUpper(joinWords(parts, "_"))
+}
+
+// FileStoreOption configures the keystores that read structured files, such as NewPropertiesKeyStore.
+type FileStoreOption func(*fileStoreSettings)
+
+type fileStoreSettings struct {
+	// keyMapping maps names in the file to keys. If nil then names are used as they are
+	keyMapping KeyMapper
+}
+
+// WithKeyMapping sets how names in the file are mapped to keys. Use EnvStyleKeys so that db.max.conns satisfies
+// key:"DB_MAX_CONNS". Pass nil to use the names as they are.
+func WithKeyMapping(mapping KeyMapper) FileStoreOption {
+	return func(s *fileStoreSettings) { s.keyMapping = mapping }
+}
+
+// fileParser parses the content of a configuration file into entries.
+type fileParser func(filename string, content string) ([]fileEntry, error)
+
+// loadFileKeyStore reads and parses the file, returning a KeyStore for its entries with names mapped to keys.
+// If two entries map to the same key then the later one wins.
+func loadFileKeyStore(filename string, parse fileParser, defaultMapping KeyMapper, options []FileStoreOption) (KeyStore, error) {
+	settings := newFileStoreSettings(defaultMapping, options)
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parse(filename, string(content))
+	if err != nil {
+		return nil, err
+	}
+	return mapKeyStore(settings.mapEntries(entries)), nil
+}
+
+func newFileStoreSettings(defaultMapping KeyMapper, options []FileStoreOption) *fileStoreSettings {
+	settings := &fileStoreSettings{keyMapping: defaultMapping}
+	for _, opt := range options {
+		opt(settings)
+	}
+	return settings
+}
+
+// mapEntries maps the names of the entries to keys. If two entries map to the same key then the later one wins.
+func (s *fileStoreSettings) mapEntries(entries []fileEntry) map[string]string {
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		key := entry.Key
+		if s.keyMapping != nil {
+			key = s.keyMapping(key)
+		}
+		values[key] = entry.Value
+	}
+	return values
+}
+
+// mapKeyStore returns a KeyStore that reads from a fixed map of values.
+func mapKeyStore(values map[string]string) KeyStore {
+	return func(ctx context.Context, key string) (string, bool, error) {
+		val, ok := values[key]
+		return val, ok, nil
+	}
+}
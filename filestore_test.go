@@ -0,0 +1,23 @@
+package goconfig
+
+import "testing"
+
+func TestEnvStyleKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"db.max.conns", "DB_MAX_CONNS"},
+		{"db.maxConns", "DB_MAX_CONNS"},
+		{"server-port", "SERVER_PORT"},
+		{"database.host_name", "DATABASE_HOST_NAME"},
+		{"HTTPServer.port", "HTTP_SERVER_PORT"},
+		{"a..b", "A_B"},
+		{"ALREADY_ENV", "ALREADY_ENV"},
+	}
+	for _, tt := range tests {
+		if got := EnvStyleKeys(tt.name); got != tt.want {
+			t.Errorf("EnvStyleKeys(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package goconfig
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RegisterFlags registers a command line flag on the flag set for each key in the config struct, and returns a
+// KeyStore that reports the flags that were set. Place it ahead of other stores in a CompositeStore so that the
+// command line overrides them:
+//
+//	flags, err := goconfig.RegisterFlags(flag.CommandLine, &cfg)
+//	flag.Parse()
+//	err = goconfig.Load(ctx, &cfg, goconfig.WithKeyStore(goconfig.CompositeStore(flags, goconfig.EnvironmentKeyStore)))
+//
+// The struct is walked in the same way as Load, so pass the same WithKeyPrefix, WithKeyNaming and WithCustomType
+// options. The flag name comes from the flag tag, or is derived from the key so that DATABASE_URL becomes
+// -database-url. A flag tag of "-" registers no flag. The desc tag gives the help text and the default tag is shown
+// as the default, though Load applies the default itself. The config struct is not modified.
+func RegisterFlags(flags *flag.FlagSet, config any, options ...Option) (KeyStore, error) {
+	t := reflect.TypeOf(config)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a pointer to a struct")
+	}
+
+	opts := newLoadOptions()
+	opts.applyOptions(options)
+
+	values := make(map[string]*flagValue)
+	if err := registerStructFlags(flags, reflect.New(t.Elem()).Elem(), structScope{keyPrefix: opts.keyPrefix}, opts, values); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, key string) (string, bool, error) {
+		if value, ok := values[key]; ok && value.set {
+			return value.value, true, nil
+		}
+		return "", false, nil
+	}, nil
+}
+
+// registerStructFlags recursively registers flags for the fields of a struct.
+func registerStructFlags(flags *flag.FlagSet, v reflect.Value, scope structScope, opts *loadOptions, values map[string]*flagValue) error {
+	fields, err := structFields(v, scope, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if f.nested {
+			if err := registerStructFlags(flags, f.value, f.scope, opts, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, hasName := f.field.Tag.Lookup("flag")
+		if name == "-" {
+			continue
+		}
+		if !hasName {
+			name = flagNameForKey(f.keys.primary)
+		}
+		key := f.keys.primary
+		if _, exists := values[key]; exists {
+			// Another field reads the same key, so it shares the flag
+			continue
+		}
+		if flags.Lookup(name) != nil {
+			return fmt.Errorf("field %s: flag %s is already defined", f.scope.fieldPath, name)
+		}
+
+		value := &flagValue{
+			defaultValue: f.field.Tag.Get("default"),
+			isBool:       isBoolType(f.field.Type),
+		}
+		flags.Var(value, name, f.field.Tag.Get("desc"))
+		values[key] = value
+	}
+	return nil
+}
+
+// flagNameForKey derives a flag name from a key, so DATABASE_URL becomes database-url.
+func flagNameForKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(key))
+}
+
+func isBoolType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
+
+// flagValue is a flag.Value that records the raw string so that it can be parsed by Load.
+type flagValue struct {
+	value        string
+	set          bool
+	defaultValue string
+	isBool       bool
+}
+
+// String returns the value, or the default if the flag has not been set. This is shown in the help text.
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	if f.set {
+		return f.value
+	}
+	return f.defaultValue
+}
+
+func (f *flagValue) Set(value string) error {
+	f.value = value
+	f.set = true
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be given without a value, as in -verbose.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
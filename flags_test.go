@@ -0,0 +1,137 @@
+package goconfig
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	ctx := context.Background()
+
+	type Database struct {
+		URL      string `key:"DATABASE_URL" desc:"Database connection string"`
+		MaxConns int    `default:"10" desc:"Maximum connections"`
+	}
+	type Config struct {
+		Port     int           `key:"PORT" default:"8080" desc:"Port to listen on"`
+		Verbose  bool          `key:"VERBOSE" flag:"v" desc:"Verbose logging"`
+		Timeout  time.Duration `key:"TIMEOUT"`
+		Secret   string        `key:"SECRET" flag:"-"`
+		Database Database      `prefix:"DB_"`
+	}
+
+	newFlags := func(t *testing.T, options ...Option) (*flag.FlagSet, KeyStore) {
+		t.Helper()
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		store, err := RegisterFlags(flags, &Config{}, options...)
+		if err != nil {
+			t.Fatalf("RegisterFlags() error = %v", err)
+		}
+		return flags, store
+	}
+
+	t.Run("Flags are registered", func(t *testing.T) {
+		flags, _ := newFlags(t, WithKeyNaming(SnakeCaseKeys))
+		for _, name := range []string{"port", "v", "timeout", "db-database-url", "db-max-conns"} {
+			if flags.Lookup(name) == nil {
+				t.Errorf("Expected flag %s", name)
+			}
+		}
+		if flags.Lookup("secret") != nil {
+			t.Error("Expected no flag for SECRET")
+		}
+		if f := flags.Lookup("port"); f.Usage != "Port to listen on" || f.DefValue != "8080" {
+			t.Errorf("Unexpected port flag %+v", f)
+		}
+	})
+
+	t.Run("Help text", func(t *testing.T) {
+		flags, _ := newFlags(t)
+		var buf bytes.Buffer
+		flags.SetOutput(&buf)
+		flags.PrintDefaults()
+		help := buf.String()
+		if !strings.Contains(help, "Port to listen on (default 8080)") {
+			t.Errorf("Expected default in help, got %s", help)
+		}
+		if strings.Contains(help, "(default )") {
+			t.Errorf("Unexpected empty default in help, got %s", help)
+		}
+	})
+
+	t.Run("Only set flags are present", func(t *testing.T) {
+		flags, store := newFlags(t)
+		if err := flags.Parse([]string{"-port", "9000", "-v"}); err != nil {
+			t.Fatal(err)
+		}
+		if val, ok, err := store(ctx, "PORT"); err != nil || !ok || val != "9000" {
+			t.Errorf("PORT: got (%q, %v, %v)", val, ok, err)
+		}
+		if val, ok, _ := store(ctx, "VERBOSE"); !ok || val != "true" {
+			t.Errorf("VERBOSE: got (%q, %v)", val, ok)
+		}
+		if _, ok, _ := store(ctx, "TIMEOUT"); ok {
+			t.Error("TIMEOUT should not be present")
+		}
+		if _, ok, _ := store(ctx, "DB_DATABASE_URL"); ok {
+			t.Error("DB_DATABASE_URL should not be present")
+		}
+	})
+
+	t.Run("Flags override other stores in Load", func(t *testing.T) {
+		flags, store := newFlags(t)
+		if err := flags.Parse([]string{"-db-database-url", "postgres://flag", "-timeout=5s"}); err != nil {
+			t.Fatal(err)
+		}
+		env := mapKeyStore(map[string]string{"DB_DATABASE_URL": "postgres://env", "PORT": "7000"})
+
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(CompositeStore(store, env))); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Database.URL != "postgres://flag" || cfg.Port != 7000 || cfg.Timeout != 5*time.Second {
+			t.Errorf("Got %+v", cfg)
+		}
+	})
+
+	t.Run("Key prefix", func(t *testing.T) {
+		flags, _ := newFlags(t, WithKeyPrefix("APP_"))
+		if flags.Lookup("app-port") == nil {
+			t.Error("Expected flag app-port")
+		}
+	})
+
+	t.Run("Config is not modified", func(t *testing.T) {
+		type PtrConfig struct {
+			Database *Database
+		}
+		cfg := PtrConfig{}
+		if _, err := RegisterFlags(flag.NewFlagSet("test", flag.ContinueOnError), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Database != nil {
+			t.Error("Expected config to be left alone")
+		}
+	})
+
+	t.Run("Conflicting flag names", func(t *testing.T) {
+		type Conflict struct {
+			A string `key:"A" flag:"name"`
+			B string `key:"B" flag:"name"`
+		}
+		_, err := RegisterFlags(flag.NewFlagSet("test", flag.ContinueOnError), &Conflict{})
+		if err == nil || !strings.Contains(err.Error(), "flag name is already defined") {
+			t.Errorf("Expected conflict error, got %v", err)
+		}
+	})
+
+	t.Run("Not a struct pointer", func(t *testing.T) {
+		if _, err := RegisterFlags(flag.NewFlagSet("test", flag.ContinueOnError), Config{}); err == nil {
+			t.Error("Expected error")
+		}
+	})
+}
@@ -0,0 +1,238 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DocumentFormat is the format of a configuration document fetched by NewHTTPDocumentKeyStore.
+type DocumentFormat struct {
+	parse          fileParser
+	defaultMapping KeyMapper
+}
+
+// The document formats, parsed in the same way as the file keystores.
+var (
+	// DotEnvFormat is parsed like NewEnvFileKeyStore, using names as keys.
+	DotEnvFormat = DocumentFormat{parse: parseDotEnv}
+	// PropertiesFormat is parsed like NewPropertiesKeyStore, mapping names with EnvStyleKeys.
+	PropertiesFormat = DocumentFormat{parse: parseProperties, defaultMapping: EnvStyleKeys}
+	// JSONFormat is parsed like NewJSONKeyStore, mapping names with EnvStyleKeys.
+	JSONFormat = DocumentFormat{parse: parseJSONDocument, defaultMapping: EnvStyleKeys}
+	// TOMLFormat is parsed like NewTOMLKeyStore, mapping names with EnvStyleKeys.
+	TOMLFormat = DocumentFormat{parse: parseTOMLDocument, defaultMapping: EnvStyleKeys}
+	// INIFormat is parsed like NewINIKeyStore, mapping names with EnvStyleKeys.
+	INIFormat = DocumentFormat{parse: parseINI, defaultMapping: EnvStyleKeys}
+)
+
+// HTTPDocumentOption configures NewHTTPDocumentKeyStore.
+type HTTPDocumentOption func(*httpDocumentSettings)
+
+type httpDocumentSettings struct {
+	client      *http.Client
+	header      http.Header
+	maxAge      time.Duration
+	maxAgeSet   bool
+	maxSize     int64
+	fileOptions []FileStoreOption
+}
+
+// WithDocumentHTTPClient sets the HTTP client, for example to configure TLS or a timeout. The default is
+// http.DefaultClient.
+func WithDocumentHTTPClient(client *http.Client) HTTPDocumentOption {
+	return func(s *httpDocumentSettings) { s.client = client }
+}
+
+// WithDocumentHeader adds a header to each request, such as Authorization.
+func WithDocumentHeader(name, value string) HTTPDocumentOption {
+	return func(s *httpDocumentSettings) { s.header.Add(name, value) }
+}
+
+// WithDocumentMaxAge sets how long a fetched document is used before it is revalidated, overriding the max-age
+// sent by the server.
+func WithDocumentMaxAge(maxAge time.Duration) HTTPDocumentOption {
+	return func(s *httpDocumentSettings) {
+		s.maxAge = maxAge
+		s.maxAgeSet = true
+	}
+}
+
+// WithDocumentMaxSize limits the size of the document. The default is 1 MiB.
+func WithDocumentMaxSize(bytes int64) HTTPDocumentOption {
+	return func(s *httpDocumentSettings) { s.maxSize = bytes }
+}
+
+// WithDocumentFileOptions applies file store options, such as WithKeyMapping, to the document.
+func WithDocumentFileOptions(options ...FileStoreOption) HTTPDocumentOption {
+	return func(s *httpDocumentSettings) { s.fileOptions = append(s.fileOptions, options...) }
+}
+
+// httpDocument is the cached copy of a document.
+type httpDocument struct {
+	values  map[string]string
+	etag    string
+	expires time.Time
+}
+
+type httpDocumentCacheKey struct {
+	settings *httpDocumentSettings
+}
+
+// NewHTTPDocumentKeyStore returns a KeyStore that fetches a configuration document from a URL and parses it in the
+// given format.
+//
+// The document is cached. Once it is older than its max-age, taken from the Cache-Control header or
+// WithDocumentMaxAge, it is revalidated with If-None-Match so that an unchanged document is not downloaded again.
+// Without a max-age the document is revalidated once per Load. Requests use the context passed to the KeyStore.
+// A response other than 2xx or 304 is returned from the KeyStore as an *HTTPStatusError. Documents larger than
+// WithDocumentMaxSize are rejected.
+//
+//	store := goconfig.NewHTTPDocumentKeyStore("https://config.internal/myapp/production.json", goconfig.JSONFormat,
+//	    goconfig.WithDocumentHeader("Authorization", "Bearer "+token))
+func NewHTTPDocumentKeyStore(documentURL string, format DocumentFormat, options ...HTTPDocumentOption) KeyStore {
+	settings := &httpDocumentSettings{
+		client:  http.DefaultClient,
+		header:  make(http.Header),
+		maxSize: 1024 * 1024,
+	}
+	for _, opt := range options {
+		opt(settings)
+	}
+	fileSettings := newFileStoreSettings(format.defaultMapping, settings.fileOptions)
+
+	var mu sync.Mutex
+	var cached *httpDocument
+
+	fetch := func(ctx context.Context) (map[string]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if cached != nil && time.Now().Before(cached.expires) {
+			return cached.values, nil
+		}
+		var etag string
+		if cached != nil {
+			etag = cached.etag
+		}
+		document, err := settings.fetch(ctx, documentURL, etag, format.parse, fileSettings)
+		if err != nil {
+			return nil, err
+		}
+		if document.values == nil {
+			// Not modified
+			document.values = cached.values
+		}
+		cached = document
+		return cached.values, nil
+	}
+
+	return func(ctx context.Context, key string) (string, bool, error) {
+		values, err := fetchOncePerLoad(ctx, httpDocumentCacheKey{settings: settings}, func() (any, error) {
+			return fetch(ctx)
+		})
+		if err != nil {
+			return "", false, ConfigError{Key: key, Err: err}
+		}
+		value, ok := values.(map[string]string)[key]
+		return value, ok, nil
+	}
+}
+
+// fetch requests the document. If the server reports that the document with the given ETag has not been modified,
+// the returned document has nil values.
+func (s *httpDocumentSettings) fetch(ctx context.Context, documentURL, etag string, parse fileParser, fileSettings *fileStoreSettings) (*httpDocument, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, documentURL, nil)
+	if err != nil {
+		// The parse error quotes the URL, which may hold credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("invalid document URL: %w", err)
+	}
+	for name, values := range s.header {
+		req.Header[name] = values
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	name := redactedURL(req.URL)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The client's error quotes the whole URL, which may hold credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("fetching %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	document := &httpDocument{
+		etag:    resp.Header.Get("ETag"),
+		expires: time.Now().Add(s.documentMaxAge(resp.Header)),
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && etag != "":
+		if document.etag == "" {
+			document.etag = etag
+		}
+		return document, nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, &HTTPStatusError{URL: name, StatusCode: resp.StatusCode}
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, s.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if int64(len(content)) > s.maxSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", name, s.maxSize)
+	}
+	entries, err := parse(name, string(content))
+	if err != nil {
+		return nil, err
+	}
+	document.values = fileSettings.mapEntries(entries)
+	return document, nil
+}
+
+// documentMaxAge returns the configured max age, or the max-age from the Cache-Control header.
+func (s *httpDocumentSettings) documentMaxAge(header http.Header) time.Duration {
+	if s.maxAgeSet {
+		return s.maxAge
+	}
+	var maxAge time.Duration
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return 0
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return maxAge
+}
+
+// redactedURL returns the URL without credentials or query string, for use in errors.
+func redactedURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+	redacted.ForceQuery = false
+	redacted.Fragment = ""
+	return redacted.String()
+}
@@ -0,0 +1,226 @@
+package goconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDocumentServer serves documents by path with an ETag, answering If-None-Match with 304.
+type fakeDocumentServer struct {
+	mu           sync.Mutex
+	documents    map[string]string
+	cacheControl string
+	requests     int
+	downloads    int
+	lastHeader   http.Header
+}
+
+func newFakeDocumentServer(t *testing.T) (*fakeDocumentServer, *httptest.Server) {
+	fake := &fakeDocumentServer{
+		documents: map[string]string{
+			"/app.env":        "PORT=8080\nHOST=localhost\n",
+			"/app.json":       `{"database": {"port": 5432}, "name": "app"}`,
+			"/app.properties": "db.host = db.internal\n",
+			"/broken.json":    `{"port": }`,
+		},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeDocumentServer) set(path, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.documents[path] = content
+}
+
+func (f *fakeDocumentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	f.lastHeader = r.Header.Clone()
+
+	document, ok := f.documents[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(document)))
+	w.Header().Set("ETag", etag)
+	if f.cacheControl != "" {
+		w.Header().Set("Cache-Control", f.cacheControl)
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	f.downloads++
+	_, _ = w.Write([]byte(document))
+}
+
+func TestHTTPDocumentKeyStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Formats", func(t *testing.T) {
+		_, server := newFakeDocumentServer(t)
+		tests := []struct {
+			name    string
+			path    string
+			format  DocumentFormat
+			options []HTTPDocumentOption
+			key     string
+			want    string
+		}{
+			{"Env", "/app.env", DotEnvFormat, nil, "HOST", "localhost"},
+			{"JSON", "/app.json", JSONFormat, nil, "DATABASE_PORT", "5432"},
+			{"Properties", "/app.properties", PropertiesFormat, nil, "DB_HOST", "db.internal"},
+			{"Key mapping", "/app.properties", PropertiesFormat,
+				[]HTTPDocumentOption{WithDocumentFileOptions(WithKeyMapping(nil))}, "db.host", "db.internal"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				store := NewHTTPDocumentKeyStore(server.URL+tt.path, tt.format, tt.options...)
+				if value, ok, err := store(ctx, tt.key); err != nil || !ok || value != tt.want {
+					t.Errorf("store(%s) = (%q, %v, %v), want %q", tt.key, value, ok, err, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Revalidates with ETag", func(t *testing.T) {
+		fake, server := newFakeDocumentServer(t)
+		store := NewHTTPDocumentKeyStore(server.URL+"/app.env", DotEnvFormat,
+			WithDocumentHeader("Authorization", "Bearer token"))
+
+		for i := 0; i < 2; i++ {
+			if value, _, err := store(ctx, "PORT"); err != nil || value != "8080" {
+				t.Fatalf("store(PORT) = (%q, %v)", value, err)
+			}
+		}
+		if fake.requests != 2 || fake.downloads != 1 {
+			t.Errorf("Expected 2 requests and 1 download, got %d and %d", fake.requests, fake.downloads)
+		}
+		if fake.lastHeader.Get("If-None-Match") == "" || fake.lastHeader.Get("Authorization") != "Bearer token" {
+			t.Errorf("Unexpected request headers %v", fake.lastHeader)
+		}
+
+		fake.set("/app.env", "PORT=9090\n")
+		if value, _, err := store(ctx, "PORT"); err != nil || value != "9090" {
+			t.Errorf("Expected the changed document, got (%q, %v)", value, err)
+		}
+	})
+
+	t.Run("Max age", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			cacheControl string
+			options      []HTTPDocumentOption
+			wantRequests int
+		}{
+			{"Cache-Control max-age", "public, max-age=60", nil, 1},
+			{"No cache", "max-age=60, no-cache", nil, 3},
+			{"Option overrides", "max-age=60", []HTTPDocumentOption{WithDocumentMaxAge(0)}, 3},
+			{"Option sets max age", "", []HTTPDocumentOption{WithDocumentMaxAge(time.Minute)}, 1},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				fake, server := newFakeDocumentServer(t)
+				fake.cacheControl = tt.cacheControl
+				store := NewHTTPDocumentKeyStore(server.URL+"/app.env", DotEnvFormat, tt.options...)
+				for i := 0; i < 3; i++ {
+					if _, _, err := store(ctx, "PORT"); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if fake.requests != tt.wantRequests {
+					t.Errorf("Expected %d requests, got %d", tt.wantRequests, fake.requests)
+				}
+			})
+		}
+	})
+
+	t.Run("Once per Load", func(t *testing.T) {
+		fake, server := newFakeDocumentServer(t)
+		type Config struct {
+			Port int    `key:"PORT"`
+			Host string `key:"HOST"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(NewHTTPDocumentKeyStore(server.URL+"/app.env", DotEnvFormat))); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Port != 8080 || cfg.Host != "localhost" || fake.requests != 1 {
+			t.Errorf("Got %+v after %d requests", cfg, fake.requests)
+		}
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		_, server := newFakeDocumentServer(t)
+		store := NewHTTPDocumentKeyStore(server.URL+"/missing.env?token=secret", DotEnvFormat)
+		_, _, err := store(ctx, "PORT")
+
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected HTTPStatusError, got %v", err)
+		}
+		var configErr ConfigError
+		if !errors.As(err, &configErr) || configErr.Key != "PORT" {
+			t.Errorf("Expected ConfigError for PORT, got %v", err)
+		}
+		if strings.Contains(err.Error(), "secret") || statusErr.URL != server.URL+"/missing.env" {
+			t.Errorf("Expected URL without query, got %v", err)
+		}
+	})
+
+	t.Run("Size limit", func(t *testing.T) {
+		fake, server := newFakeDocumentServer(t)
+		fake.set("/large.env", "PORT=8080\n"+strings.Repeat("# padding\n", 100))
+		tests := []struct {
+			name    string
+			maxSize int64
+			wantErr bool
+		}{
+			{"Within limit", 2048, false},
+			{"Too large", 512, true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				store := NewHTTPDocumentKeyStore(server.URL+"/large.env", DotEnvFormat, WithDocumentMaxSize(tt.maxSize))
+				_, _, err := store(ctx, "PORT")
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("store() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr && !strings.Contains(err.Error(), "larger than 512 bytes") {
+					t.Errorf("Unexpected error %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		_, server := newFakeDocumentServer(t)
+		_, _, err := NewHTTPDocumentKeyStore(server.URL+"/broken.json", JSONFormat)(ctx, "PORT")
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.File != server.URL+"/broken.json" {
+			t.Errorf("Expected SyntaxError naming the URL, got %v", err)
+		}
+	})
+
+	t.Run("Connection error", func(t *testing.T) {
+		_, server := newFakeDocumentServer(t)
+		server.Close()
+		_, _, err := NewHTTPDocumentKeyStore(server.URL+"/app.env?token=secret", DotEnvFormat)(ctx, "PORT")
+		if err == nil || strings.Contains(err.Error(), "secret") {
+			t.Errorf("Expected error without the query, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package goconfig
+
+import (
+	"strings"
+)
+
+// NewINIKeyStore reads an INI file and returns a KeyStore for its values. Names are qualified by their section,
+// so host in the [database] section is named database.host, and by default mapped with EnvStyleKeys to
+// DATABASE_HOST. Use WithKeyMapping to change this.
+//
+// Lines starting with ; or # are comments, as is anything after a ; or # that follows whitespace in an unquoted
+// value. Values may be double quoted, supporting the escapes \n \t \\ and \", or single quoted to be taken
+// literally. Sections may be repeated and are merged. If a name is repeated then the last value wins.
+// Git style subsections such as [remote "origin"] are named remote.origin.
+func NewINIKeyStore(filename string, options ...FileStoreOption) (KeyStore, error) {
+	return loadFileKeyStore(filename, parseINI, EnvStyleKeys, options)
+}
+
+// parseINI parses INI content into names qualified by section.
+func parseINI(filename string, content string) ([]fileEntry, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	errorAt := func(line, column int, msg string) error {
+		return &SyntaxError{File: filename, Line: line, Column: column, Msg: msg}
+	}
+
+	var entries []fileEntry
+	section := ""
+	for i, text := range strings.Split(content, "\n") {
+		lineNumber := i + 1
+		start := len(text) - len(strings.TrimLeft(text, " \t"))
+		line := strings.TrimSpace(text)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return nil, errorAt(lineNumber, start+1, "missing ] in section header")
+			}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+				return nil, errorAt(lineNumber, start+end+2, "unexpected characters after section header")
+			}
+			name, err := parseINISectionName(line[1:end])
+			if err != nil {
+				return nil, errorAt(lineNumber, start+2, err.Error())
+			}
+			section = name
+			continue
+		}
+
+		separator := strings.IndexAny(line, "=:")
+		if separator < 0 {
+			return nil, errorAt(lineNumber, start+1, "expected = or : after name")
+		}
+		name := strings.TrimSpace(line[:separator])
+		if name == "" {
+			return nil, errorAt(lineNumber, start+1, "expected a name")
+		}
+		valueText := line[separator+1:]
+		valueColumn := start + separator + 2 + len(valueText) - len(strings.TrimLeft(valueText, " \t"))
+		value, err := parseINIValue(strings.TrimSpace(valueText))
+		if err != nil {
+			return nil, errorAt(lineNumber, valueColumn, err.Error()+" for "+name)
+		}
+
+		if section != "" {
+			name = section + "." + name
+		}
+		entries = append(entries, fileEntry{Key: name, Value: value, Line: lineNumber})
+	}
+	return entries, nil
+}
+
+// iniError is a message for a SyntaxError, positioned by the caller.
+type iniError string
+
+func (e iniError) Error() string { return string(e) }
+
+// parseINISectionName parses a section name, converting a git style [section "subsection"] to section.subsection.
+func parseINISectionName(header string) (string, error) {
+	header = strings.TrimSpace(header)
+	if name, sub, found := strings.Cut(header, " "); found {
+		sub = strings.TrimSpace(sub)
+		if len(sub) < 2 || sub[0] != '"' || sub[len(sub)-1] != '"' {
+			return "", iniError("subsection name must be quoted")
+		}
+		header = name + "." + sub[1:len(sub)-1]
+	}
+	if header == "" {
+		return "", iniError("empty section name")
+	}
+	return header, nil
+}
+
+// parseINIValue removes quotes and inline comments from a trimmed value.
+func parseINIValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch value[0] {
+	case '\'':
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", iniError("unterminated single quoted value")
+		}
+		return value[1 : end+1], checkINITrailer(value[end+2:])
+	case '"':
+		var result strings.Builder
+		for i := 1; i < len(value); i++ {
+			c := value[i]
+			switch {
+			case c == '"':
+				return result.String(), checkINITrailer(value[i+1:])
+			case c == '\\' && i+1 < len(value):
+				i++
+				switch value[i] {
+				case 'n':
+					result.WriteByte('\n')
+				case 't':
+					result.WriteByte('\t')
+				case '\\', '"':
+					result.WriteByte(value[i])
+				default:
+					result.WriteByte('\\')
+					result.WriteByte(value[i])
+				}
+			default:
+				result.WriteByte(c)
+			}
+		}
+		return "", iniError("unterminated double quoted value")
+	}
+
+	// An inline comment must follow whitespace so that values such as colour=#fff survive
+	for i := 1; i < len(value); i++ {
+		if (value[i] == ';' || value[i] == '#') && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return strings.TrimSpace(value[:i]), nil
+		}
+	}
+	return value, nil
+}
+
+// checkINITrailer allows only a comment after a quoted value.
+func checkINITrailer(trailer string) error {
+	trailer = strings.TrimSpace(trailer)
+	if trailer != "" && trailer[0] != ';' && trailer[0] != '#' {
+		return iniError("unexpected characters after quoted value")
+	}
+	return nil
+}
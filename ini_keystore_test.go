@@ -0,0 +1,153 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseINI(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []fileEntry
+		wantErr *SyntaxError
+	}{
+		{
+			name:  "sections",
+			input: "global=1\n[database]\nhost = localhost\nport: 5432\n\n[ server ]\nport=8080",
+			want: []fileEntry{
+				{"global", "1", 1},
+				{"database.host", "localhost", 3},
+				{"database.port", "5432", 4},
+				{"server.port", "8080", 7},
+			},
+		},
+		{
+			name:  "comments",
+			input: "; comment\n# comment\n[a] ; comment\nx = 1 ; comment\ny = 2 # comment\ncolour=#fff\nurl=http://a;b",
+			want: []fileEntry{
+				{"a.x", "1", 4},
+				{"a.y", "2", 5},
+				{"a.colour", "#fff", 6},
+				{"a.url", "http://a;b", 7},
+			},
+		},
+		{
+			name:  "quoted values",
+			input: `a = "  spaced ; not comment "` + "\n" + `b = "line\nnext \"q\" \\ \d" ; comment` + "\n" + `c = 'lit\n' # comment` + "\n" + "d =",
+			want: []fileEntry{
+				{"a", "  spaced ; not comment ", 1},
+				{"b", "line\nnext \"q\" \\ \\d", 2},
+				{"c", `lit\n`, 3},
+				{"d", "", 4},
+			},
+		},
+		{
+			name:  "duplicate sections merge",
+			input: "[db]\nhost=a\n[other]\nx=1\n[db]\nport=1\nhost=b",
+			want: []fileEntry{
+				{"db.host", "a", 2},
+				{"other.x", "1", 4},
+				{"db.port", "1", 6},
+				{"db.host", "b", 7},
+			},
+		},
+		{
+			name:  "git style subsections",
+			input: "[remote \"origin\"]\nurl=git@example.com",
+			want:  []fileEntry{{"remote.origin.url", "git@example.com", 2}},
+		},
+		{
+			name:    "missing separator",
+			input:   "[a]\n  novalue",
+			wantErr: &SyntaxError{File: "test.ini", Line: 2, Column: 3, Msg: "expected = or : after name"},
+		},
+		{
+			name:    "unterminated section",
+			input:   "[database",
+			wantErr: &SyntaxError{File: "test.ini", Line: 1, Column: 1, Msg: "missing ] in section header"},
+		},
+		{
+			name:    "empty section",
+			input:   "[ ]",
+			wantErr: &SyntaxError{File: "test.ini", Line: 1, Column: 2, Msg: "empty section name"},
+		},
+		{
+			name:    "unterminated quote",
+			input:   `key =  "abc`,
+			wantErr: &SyntaxError{File: "test.ini", Line: 1, Column: 8, Msg: "unterminated double quoted value for key"},
+		},
+		{
+			name:    "text after quote",
+			input:   `key='a' b`,
+			wantErr: &SyntaxError{File: "test.ini", Line: 1, Column: 5, Msg: "unexpected characters after quoted value for key"},
+		},
+		{
+			name:    "missing name",
+			input:   "= value",
+			wantErr: &SyntaxError{File: "test.ini", Line: 1, Column: 1, Msg: "expected a name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseINI("test.ini", tt.input)
+			if tt.wantErr != nil {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) || *syntaxErr != *tt.wantErr {
+					t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewINIKeyStore(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "app.ini")
+	content := "[database]\nhost=localhost\nmax-conns=10\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Env style keys by default", func(t *testing.T) {
+		store, err := NewINIKeyStore(filename)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		type Config struct {
+			Database struct {
+				Host     string
+				MaxConns int
+			}
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(CompositeStore(EnvironmentKeyStore, store)), WithKeyNaming(SnakeCaseKeys)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Database.Host != "localhost" || cfg.Database.MaxConns != 10 {
+			t.Errorf("Got %+v", cfg)
+		}
+	})
+
+	t.Run("Names as they are", func(t *testing.T) {
+		store, err := NewINIKeyStore(filename, WithKeyMapping(nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if val, ok, _ := store(ctx, "database.max-conns"); !ok || val != "10" {
+			t.Errorf("Got (%q, %v)", val, ok)
+		}
+	})
+}
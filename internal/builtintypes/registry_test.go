@@ -193,6 +193,8 @@ func TestDefaultHandlers(t *testing.T) {
 		{"URL", (*url.URL)(nil)},
 		{"Struct", struct{ X int }{}},
 		{"Map", map[string]int{}},
+		{"Slice", []int{}},
+		{"Array", [2]string{}},
 	}
 
 	for _, tt := range tests {
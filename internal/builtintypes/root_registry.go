@@ -29,12 +29,40 @@ func (r *RootTypeRegistry) RegisterKind(kind reflect.Kind, factory readpipeline.
 
 // HandlerFor returns the readpipeline.PipelineBuilder for the given type, or nil if none is registered.
 func (r *RootTypeRegistry) HandlerFor(t reflect.Type) readpipeline.PipelineBuilder {
+	return r.handlerForScope(t, r)
+}
+
+// HasTypeHandler reports whether a handler is registered for the specific type, or the type implements one
+// of the unmarshaler interfaces.
+func (r *RootTypeRegistry) HasTypeHandler(t reflect.Type) bool {
+	_, ok := r.specialTypeHandlers[t]
+	return ok || unmarshalerFor(t) != nil
+}
+
+// handlerForScope resolves the handler for the given type. Collection handlers look up their element types in scope,
+// which is the registry the original request was made to, so that local registrations apply to elements too.
+func (r *RootTypeRegistry) handlerForScope(t reflect.Type, scope readpipeline.TypeRegistry) readpipeline.PipelineBuilder {
 	// 1. Check for specific type overrides (The "Duration" check)
 	if p, ok := r.specialTypeHandlers[t]; ok {
 		return p
 	}
 
-	// 2. Fall back to category-based logic
+	// 2. Types that know how to decode themselves, such as time.Time, netip.Addr and slog.Level
+	if p := NewUnmarshalerHandler(t, scope); p != nil {
+		return p
+	}
+
+	// 3. Byte slices take the raw value, so that keys and secrets can be loaded into them
+	if isByteSlice(t, scope) {
+		return NewByteSliceHandler(t)
+	}
+
+	// 4. Slices and arrays are split and each element passed through the element type's handler
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return NewSliceHandler(t, scope)
+	}
+
+	// 5. Fall back to category-based logic
 	if factory, ok := r.kindHandlers[t.Kind()]; ok {
 		return factory(t)
 	}
@@ -68,12 +96,32 @@ func (r *LocalTypeRegistry) RegisterType(t reflect.Type, handler readpipeline.Pi
 }
 
 func (r *LocalTypeRegistry) HandlerFor(t reflect.Type) readpipeline.PipelineBuilder {
+	return r.handlerForScope(t, r)
+}
+
+func (r *LocalTypeRegistry) HasTypeHandler(t reflect.Type) bool {
+	if _, ok := r.SpecialTypeHandlers[t]; ok {
+		return true
+	}
+	return r.Parent != nil && r.Parent.HasTypeHandler(t)
+}
+
+func (r *LocalTypeRegistry) handlerForScope(t reflect.Type, scope readpipeline.TypeRegistry) readpipeline.PipelineBuilder {
 	if p, ok := r.SpecialTypeHandlers[t]; ok {
 		return p
 	}
+	if parent, ok := r.Parent.(scopedTypeRegistry); ok {
+		return parent.handlerForScope(t, scope)
+	}
 	return r.Parent.HandlerFor(t)
 }
 
+// scopedTypeRegistry is implemented by registries in this package so that a child registry can pass itself down
+// as the scope for element type lookups.
+type scopedTypeRegistry interface {
+	handlerForScope(t reflect.Type, scope readpipeline.TypeRegistry) readpipeline.PipelineBuilder
+}
+
 var rootRegistry = &RootTypeRegistry{
 	specialTypeHandlers: map[reflect.Type]readpipeline.PipelineBuilder{
 		reflect.TypeOf(time.Duration(0)): readpipeline.WrapTypedHandler(durationTypeHandler),
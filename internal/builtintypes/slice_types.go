@@ -0,0 +1,219 @@
+package builtintypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/m0rjc/goconfig/internal/readpipeline"
+)
+
+// defaultSeparator is used to split collection values if no sep tag is given.
+const defaultSeparator = ","
+
+// sliceHandler builds pipelines for slice and array types.
+// The raw value is split on the sep tag, or decoded as a JSON array with format:"json", and each element is processed by the element type's own pipeline, so
+// element validation tags such as min, max and pattern apply to each item.
+// The minItems, maxItems and unique tags apply to the collection as a whole.
+type sliceHandler struct {
+	collectionType reflect.Type
+	registry       readpipeline.TypeRegistry
+}
+
+// NewSliceHandler returns a PipelineBuilder for the given slice or array type.
+// Element handlers are resolved through the given registry so that custom types apply to elements too.
+func NewSliceHandler(collectionType reflect.Type, registry readpipeline.TypeRegistry) readpipeline.PipelineBuilder {
+	return &sliceHandler{collectionType: collectionType, registry: registry}
+}
+
+func (h *sliceHandler) Build(tags reflect.StructTag) (readpipeline.FieldProcessor[any], error) {
+	elemType := h.collectionType.Elem()
+	elementPipeline, err := readpipeline.New(elemType, tags, h.registry)
+	if err != nil {
+		return nil, fmt.Errorf("element: %w", err)
+	}
+
+	separator := defaultSeparator
+	if sepTag, ok := tags.Lookup("sep"); ok {
+		if sepTag == "" {
+			return nil, fmt.Errorf("sep tag must not be empty")
+		}
+		separator = sepTag
+	}
+
+	jsonItems := false
+	if format, ok := tags.Lookup("format"); ok {
+		if format != "json" {
+			return nil, fmt.Errorf("format tag must be json")
+		}
+		if _, ok := tags.Lookup("sep"); ok {
+			return nil, fmt.Errorf("sep tag does not apply to format json")
+		}
+		jsonItems = true
+	}
+
+	minItems, err := collectionSizeTag(tags, "minItems")
+	if err != nil {
+		return nil, err
+	}
+	maxItems, err := collectionSizeTag(tags, "maxItems")
+	if err != nil {
+		return nil, err
+	}
+	if h.collectionType.Kind() == reflect.Array && (maxItems < 0 || maxItems > h.collectionType.Len()) {
+		maxItems = h.collectionType.Len()
+	}
+	unique := tags.Get("unique") == "true"
+
+	return func(rawValue string) (any, error) {
+		var items []string
+		if jsonItems {
+			var err error
+			if items, err = decodeJSONItems(rawValue); err != nil {
+				return nil, err
+			}
+		} else {
+			items = splitItems(rawValue, separator)
+		}
+
+		if minItems >= 0 && len(items) < minItems {
+			return nil, fmt.Errorf("must have at least %d items", minItems)
+		}
+		if maxItems >= 0 && len(items) > maxItems {
+			return nil, fmt.Errorf("must have at most %d items", maxItems)
+		}
+
+		result := h.newCollection(len(items))
+		seen := make(map[any]bool)
+		for i, item := range items {
+			value, err := elementPipeline(item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+
+			if unique {
+				var identity any = item
+				if value != nil && reflect.TypeOf(value).Comparable() {
+					identity = value
+				}
+				if seen[identity] {
+					return nil, fmt.Errorf("item %d: duplicate item", i)
+				}
+				seen[identity] = true
+			}
+
+			element, err := convertElement(value, elemType)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			if h.collectionType.Kind() == reflect.Array {
+				result.Index(i).Set(element)
+			} else {
+				result = reflect.Append(result, element)
+			}
+		}
+		return result.Interface(), nil
+	}, nil
+}
+
+// NewByteSliceHandler returns a PipelineBuilder for []byte and named byte slice types. The raw value is taken as the bytes rather than split into numbers, which suits keys and secrets.
+// The tags for strings, such as pattern, validate the value.
+func NewByteSliceHandler(sliceType reflect.Type) readpipeline.PipelineBuilder {
+	return readpipeline.WrapTypedHandler[any](&typeHandlerImpl[any]{
+		Parser: func(rawValue string) (any, error) {
+			return reflect.ValueOf([]byte(rawValue)).Convert(sliceType).Interface(), nil
+		},
+		ValidationWrapper: func(tags reflect.StructTag, next readpipeline.FieldProcessor[any]) (readpipeline.FieldProcessor[any], error) {
+			validate, err := NewTypedStringHandler().BuildPipeline(tags)
+			if err != nil {
+				return nil, err
+			}
+			return func(rawValue string) (any, error) {
+				if _, err := validate(rawValue); err != nil {
+					return nil, err
+				}
+				return next(rawValue)
+			}, nil
+		},
+	})
+}
+
+// isByteSlice reports whether the type is a slice of bytes whose element has no handler of its own.
+func isByteSlice(t reflect.Type, registry readpipeline.TypeRegistry) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !registry.HasTypeHandler(t.Elem())
+}
+
+// splitItems splits the raw value on the separator and trims each item.
+func splitItems(rawValue string, separator string) []string {
+	if strings.TrimSpace(rawValue) == "" {
+		return nil
+	}
+	items := strings.Split(rawValue, separator)
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// decodeJSONItems decodes a JSON array, as served by the document keystores, into items. String items are unquoted
+// and other items are passed on as JSON for the element pipeline.
+func decodeJSONItems(rawValue string) ([]string, error) {
+	if strings.TrimSpace(rawValue) == "" {
+		return nil, nil
+	}
+	var elements []json.RawMessage
+	if err := json.Unmarshal([]byte(rawValue), &elements); err != nil {
+		return nil, fmt.Errorf("must be a JSON array")
+	}
+	items := make([]string, len(elements))
+	for i, element := range elements {
+		var text string
+		if err := json.Unmarshal(element, &text); err == nil {
+			items[i] = text
+		} else {
+			items[i] = string(element)
+		}
+	}
+	return items, nil
+}
+
+// newCollection creates an empty slice with capacity for the given number of items, or a zero array.
+func (h *sliceHandler) newCollection(size int) reflect.Value {
+	if h.collectionType.Kind() == reflect.Array {
+		return reflect.New(h.collectionType).Elem()
+	}
+	return reflect.MakeSlice(h.collectionType, 0, size)
+}
+
+// collectionSizeTag reads a non-negative integer tag, returning -1 if it is not present.
+func collectionSizeTag(tags reflect.StructTag, name string) (int, error) {
+	tag, ok := tags.Lookup(name)
+	if !ok {
+		return -1, nil
+	}
+	size, err := strconv.Atoi(tag)
+	if err != nil || size < 0 {
+		return -1, fmt.Errorf("%s tag must be a non-negative integer", name)
+	}
+	return size, nil
+}
+
+// convertElement converts the output of an element pipeline to the element type.
+// Pipelines output values, so pointer element types are boxed here.
+func convertElement(value any, elemType reflect.Type) (reflect.Value, error) {
+	val := reflect.ValueOf(value)
+	if !val.IsValid() {
+		return reflect.Zero(elemType), nil
+	}
+	if val.Type().ConvertibleTo(elemType) {
+		return val.Convert(elemType), nil
+	}
+	if elemType.Kind() == reflect.Ptr && val.Type().ConvertibleTo(elemType.Elem()) {
+		ptr := reflect.New(elemType.Elem())
+		ptr.Elem().Set(val.Convert(elemType.Elem()))
+		return ptr, nil
+	}
+	return reflect.Value{}, fmt.Errorf("value of type %s cannot be converted to %s", val.Type(), elemType)
+}
@@ -0,0 +1,254 @@
+package builtintypes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m0rjc/goconfig/internal/readpipeline"
+)
+
+type secretBytes []byte
+
+func TestSliceTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType reflect.Type
+		tags      reflect.StructTag
+		input     string
+		want      any
+		wantErr   string
+	}{
+		{
+			name:      "strings with default separator",
+			fieldType: reflect.TypeOf([]string{}),
+			input:     "a, b ,c",
+			want:      []string{"a", "b", "c"},
+		},
+		{
+			name:      "empty input gives empty slice",
+			fieldType: reflect.TypeOf([]string{}),
+			input:     "  ",
+			want:      []string{},
+		},
+		{
+			name:      "custom separator",
+			fieldType: reflect.TypeOf([]string{}),
+			tags:      `sep:";"`,
+			input:     "a,b;c",
+			want:      []string{"a,b", "c"},
+		},
+		{
+			name:      "ints",
+			fieldType: reflect.TypeOf([]int{}),
+			input:     "1,2,3",
+			want:      []int{1, 2, 3},
+		},
+		{
+			name:      "json array is split without format tag",
+			fieldType: reflect.TypeOf([]string{}),
+			input:     `["a", "b"]`,
+			want:      []string{`["a"`, `"b"]`},
+		},
+		{
+			name:      "json array with sep is split",
+			fieldType: reflect.TypeOf([]string{}),
+			tags:      `sep:";"`,
+			input:     `["a;b"]`,
+			want:      []string{`["a`, `b"]`},
+		},
+		{
+			name:      "json array of strings keeps spaces and commas",
+			fieldType: reflect.TypeOf([]string{}),
+			tags:      `format:"json"`,
+			input:     ` ["a, b", " c "] `,
+			want:      []string{"a, b", " c "},
+		},
+		{
+			name:      "json array of numbers",
+			fieldType: reflect.TypeOf([]int{}),
+			tags:      `format:"json"`,
+			input:     "[1, 2]",
+			want:      []int{1, 2},
+		},
+		{
+			name:      "json array of objects",
+			fieldType: reflect.TypeOf([]map[string]int{}),
+			tags:      `format:"json"`,
+			input:     `[{"a":1},{"b":2}]`,
+			want:      []map[string]int{{"a": 1}, {"b": 2}},
+		},
+		{
+			name:      "json format empty input",
+			fieldType: reflect.TypeOf([]string{}),
+			tags:      `format:"json"`,
+			input:     " ",
+			want:      []string{},
+		},
+		{
+			name:      "json format rejects separated values",
+			fieldType: reflect.TypeOf([]string{}),
+			tags:      `format:"json"`,
+			input:     "a,b",
+			wantErr:   "must be a JSON array",
+		},
+		{
+			name:      "json format element validation",
+			fieldType: reflect.TypeOf([]int{}),
+			tags:      `format:"json" min:"2"`,
+			input:     "[2, 1]",
+			wantErr:   "item 1: below minimum 2",
+		},
+		{
+			name:      "durations",
+			fieldType: reflect.TypeOf([]time.Duration{}),
+			input:     "1s,2m",
+			want:      []time.Duration{time.Second, 2 * time.Minute},
+		},
+		{
+			name:      "pointer elements",
+			fieldType: reflect.TypeOf([]*int{}),
+			input:     "7",
+		},
+		{
+			name:      "element parse error",
+			fieldType: reflect.TypeOf([]int{}),
+			input:     "1,x",
+			wantErr:   "item 1:",
+		},
+		{
+			name:      "element min applies per item",
+			fieldType: reflect.TypeOf([]int{}),
+			tags:      `min:"10"`,
+			input:     "10,9",
+			wantErr:   "item 1: below minimum 10",
+		},
+		{
+			name:      "element pattern applies per item",
+			fieldType: reflect.TypeOf([]string{}),
+			tags:      `pattern:"^[a-z]+$"`,
+			input:     "abc,ABC",
+			wantErr:   "item 1: does not match pattern",
+		},
+		{
+			name:      "minItems",
+			fieldType: reflect.TypeOf([]string{}),
+			tags:      `minItems:"2"`,
+			input:     "a",
+			wantErr:   "must have at least 2 items",
+		},
+		{
+			name:      "maxItems",
+			fieldType: reflect.TypeOf([]string{}),
+			tags:      `maxItems:"2"`,
+			input:     "a,b,c",
+			wantErr:   "must have at most 2 items",
+		},
+		{
+			name:      "unique pass",
+			fieldType: reflect.TypeOf([]string{}),
+			tags:      `unique:"true"`,
+			input:     "a,b",
+			want:      []string{"a", "b"},
+		},
+		{
+			name:      "unique compares parsed values",
+			fieldType: reflect.TypeOf([]int{}),
+			tags:      `unique:"true"`,
+			input:     "1,01",
+			wantErr:   "item 1: duplicate item",
+		},
+		{
+			name:      "byte slice takes the raw value",
+			fieldType: reflect.TypeOf([]byte{}),
+			input:     "hello, world",
+			want:      []byte("hello, world"),
+		},
+		{
+			name:      "named byte slice",
+			fieldType: reflect.TypeOf(secretBytes{}),
+			input:     "s3cret",
+			want:      secretBytes("s3cret"),
+		},
+		{
+			name:      "byte slice pattern",
+			fieldType: reflect.TypeOf([]byte{}),
+			tags:      `pattern:"^[0-9a-f]+$"`,
+			input:     "xyz",
+			wantErr:   "does not match pattern",
+		},
+		{
+			name:      "array",
+			fieldType: reflect.TypeOf([3]int{}),
+			input:     "1,2",
+			want:      [3]int{1, 2, 0},
+		},
+		{
+			name:      "array too long",
+			fieldType: reflect.TypeOf([2]int{}),
+			input:     "1,2,3",
+			wantErr:   "must have at most 2 items",
+		},
+	}
+
+	registry := NewTypeRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := readpipeline.New(tt.fieldType, tt.tags, registry)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			got, err := proc(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Process() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Process() unexpected error = %v", err)
+			}
+			if reflect.TypeOf(got) != tt.fieldType {
+				t.Fatalf("Process() got type %T, want %v", got, tt.fieldType)
+			}
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Process() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSliceTypes_BadTags(t *testing.T) {
+	registry := NewTypeRegistry()
+	for _, tags := range []reflect.StructTag{`sep:""`, `minItems:"x"`, `maxItems:"-1"`, `min:"x"`, `format:"yaml"`, `format:"json" sep:";"`} {
+		t.Run(string(tags), func(t *testing.T) {
+			if _, err := readpipeline.New(reflect.TypeOf([]int{}), tags, registry); err == nil {
+				t.Errorf("expected error for tags %s", tags)
+			}
+		})
+	}
+}
+
+func TestSliceTypes_LocalElementHandler(t *testing.T) {
+	type Upper string
+	registry := NewTypeRegistry()
+	registry.RegisterType(reflect.TypeOf(Upper("")), readpipeline.WrapTypedHandler[Upper](&typeHandlerImpl[Upper]{
+		Parser: func(rawValue string) (Upper, error) {
+			return Upper(strings.ToUpper(rawValue)), nil
+		},
+	}))
+
+	proc, err := readpipeline.New(reflect.TypeOf([]Upper{}), "", registry)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	got, err := proc("a,b")
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []Upper{"A", "B"}) {
+		t.Errorf("Process() got = %v", got)
+	}
+}
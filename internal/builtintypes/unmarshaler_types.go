@@ -0,0 +1,143 @@
+package builtintypes
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/m0rjc/goconfig/internal/readpipeline"
+)
+
+var (
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType   = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
+// unmarshalFunc decodes the raw value into the value pointed to by target.
+type unmarshalFunc func(target any, rawValue []byte) error
+
+// NewUnmarshalerHandler returns a PipelineBuilder for types that implement encoding.TextUnmarshaler,
+// json.Unmarshaler or encoding.BinaryUnmarshaler on either the value or pointer receiver, or nil if the type
+// implements none of them.
+// Text is preferred as configuration values are text. JSON is preferred over binary because a binary decoder
+// is unlikely to expect text input.
+// The decoded value is validated by the registry's handler for its kind, so that min and max apply to a slog.Level
+// as to an int and pattern applies to a named string type. Validation tags on other kinds are rejected.
+func NewUnmarshalerHandler(targetType reflect.Type, registry readpipeline.TypeRegistry) readpipeline.PipelineBuilder {
+	unmarshal := unmarshalerFor(targetType)
+	if unmarshal == nil {
+		return nil
+	}
+
+	return readpipeline.WrapTypedHandler[any](&typeHandlerImpl[any]{
+		Parser: func(rawValue string) (any, error) {
+			ptr := reflect.New(targetType)
+			if err := unmarshal(ptr.Interface(), []byte(rawValue)); err != nil {
+				return nil, err
+			}
+			// Dereference the value to maintain consistency with the maxim "Pipelines always return values"
+			return ptr.Elem().Interface(), nil
+		},
+		ValidationWrapper: func(tags reflect.StructTag, processor readpipeline.FieldProcessor[any]) (readpipeline.FieldProcessor[any], error) {
+			return wrapProcessUsingKindValidation(targetType, registry, tags, processor)
+		},
+	})
+}
+
+// validationTags are the tags that the kind handlers enforce.
+var validationTags = []string{"min", "max", "pattern"}
+
+// wrapProcessUsingKindValidation validates decoded values with the handler for the type's kind, which is the handler
+// the registry gives for the predeclared type of that kind, such as int for slog.Level. The value is formatted as
+// text for the kind handler, which parses it again and applies its validation tags.
+func wrapProcessUsingKindValidation(targetType reflect.Type, registry readpipeline.TypeRegistry, tags reflect.StructTag, processor readpipeline.FieldProcessor[any]) (readpipeline.FieldProcessor[any], error) {
+	format := kindFormatter(targetType)
+	if format == nil {
+		for _, name := range validationTags {
+			if _, ok := tags.Lookup(name); ok {
+				return nil, fmt.Errorf("%s tag is not supported for %s", name, targetType)
+			}
+		}
+		return processor, nil
+	}
+
+	handler := registry.HandlerFor(kindTypes[targetType.Kind()])
+	if handler == nil {
+		return processor, nil
+	}
+	kindProcessor, err := handler.Build(tags)
+	if err != nil {
+		return nil, err
+	}
+	return readpipeline.Pipe(processor, func(value any) error {
+		_, err := kindProcessor(format(reflect.ValueOf(value)))
+		return err
+	}), nil
+}
+
+// kindTypes are the predeclared types of the kinds that kindFormatter supports.
+var kindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Int:     reflect.TypeFor[int](),
+	reflect.Int8:    reflect.TypeFor[int8](),
+	reflect.Int16:   reflect.TypeFor[int16](),
+	reflect.Int32:   reflect.TypeFor[int32](),
+	reflect.Int64:   reflect.TypeFor[int64](),
+	reflect.Uint:    reflect.TypeFor[uint](),
+	reflect.Uint8:   reflect.TypeFor[uint8](),
+	reflect.Uint16:  reflect.TypeFor[uint16](),
+	reflect.Uint32:  reflect.TypeFor[uint32](),
+	reflect.Uint64:  reflect.TypeFor[uint64](),
+	reflect.Float32: reflect.TypeFor[float32](),
+	reflect.Float64: reflect.TypeFor[float64](),
+	reflect.String:  reflect.TypeFor[string](),
+	reflect.Bool:    reflect.TypeFor[bool](),
+}
+
+// kindFormatter returns a function that formats values of the type's kind as the kind handler would parse them,
+// or nil if the kind has no such handler.
+func kindFormatter(targetType reflect.Type) func(reflect.Value) string {
+	switch targetType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value) string { return strconv.FormatInt(v.Int(), 10) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(v reflect.Value) string { return strconv.FormatUint(v.Uint(), 10) }
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value) string { return strconv.FormatFloat(v.Float(), 'g', -1, targetType.Bits()) }
+	case reflect.String:
+		return func(v reflect.Value) string { return v.String() }
+	case reflect.Bool:
+		return func(v reflect.Value) string { return strconv.FormatBool(v.Bool()) }
+	}
+	return nil
+}
+
+// unmarshalerFor returns the decoding function for the type, or nil if it does not implement an unmarshaler.
+// Pointer types are left for the caller to unbox.
+func unmarshalerFor(targetType reflect.Type) unmarshalFunc {
+	if targetType.Kind() == reflect.Ptr {
+		return nil
+	}
+
+	ptrType := reflect.PointerTo(targetType)
+	switch {
+	case ptrType.Implements(textUnmarshalerType):
+		return func(target any, rawValue []byte) error {
+			return target.(encoding.TextUnmarshaler).UnmarshalText(rawValue)
+		}
+	case ptrType.Implements(jsonUnmarshalerType):
+		return func(target any, rawValue []byte) error {
+			if err := target.(json.Unmarshaler).UnmarshalJSON(rawValue); err != nil {
+				return fmt.Errorf("error parsing json: %w", err)
+			}
+			return nil
+		}
+	case ptrType.Implements(binaryUnmarshalerType):
+		return func(target any, rawValue []byte) error {
+			return target.(encoding.BinaryUnmarshaler).UnmarshalBinary(rawValue)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package builtintypes
+
+import (
+	"errors"
+	"log/slog"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m0rjc/goconfig/internal/readpipeline"
+)
+
+// jsonOnly implements json.Unmarshaler only.
+type jsonOnly struct {
+	Raw string
+}
+
+func (j *jsonOnly) UnmarshalJSON(data []byte) error {
+	if !strings.HasPrefix(string(data), "{") {
+		return errors.New("expected an object")
+	}
+	j.Raw = string(data)
+	return nil
+}
+
+// binaryOnly implements encoding.BinaryUnmarshaler only.
+type binaryOnly []byte
+
+func (b *binaryOnly) UnmarshalBinary(data []byte) error {
+	*b = append(binaryOnly{}, data...)
+	return nil
+}
+
+func TestUnmarshalerTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType reflect.Type
+		input     string
+		want      any
+		wantErr   bool
+	}{
+		{
+			name:      "slog.Level is text rather than int",
+			fieldType: reflect.TypeOf(slog.Level(0)),
+			input:     "WARN",
+			want:      slog.LevelWarn,
+		},
+		{
+			name:      "netip.Addr is text rather than json struct",
+			fieldType: reflect.TypeOf(netip.Addr{}),
+			input:     "192.168.0.1",
+			want:      netip.MustParseAddr("192.168.0.1"),
+		},
+		{
+			name:      "netip.Addr invalid",
+			fieldType: reflect.TypeOf(netip.Addr{}),
+			input:     "not an address",
+			wantErr:   true,
+		},
+		{
+			name:      "time.Time",
+			fieldType: reflect.TypeOf(time.Time{}),
+			input:     "2025-01-02T03:04:05Z",
+			want:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name:      "pointer is unboxed",
+			fieldType: reflect.TypeOf((*netip.Prefix)(nil)),
+			input:     "10.0.0.0/8",
+			want:      netip.MustParsePrefix("10.0.0.0/8"),
+		},
+		{
+			name:      "json unmarshaler",
+			fieldType: reflect.TypeOf(jsonOnly{}),
+			input:     `{"a":1}`,
+			want:      jsonOnly{Raw: `{"a":1}`},
+		},
+		{
+			name:      "json unmarshaler error",
+			fieldType: reflect.TypeOf(jsonOnly{}),
+			input:     `[]`,
+			wantErr:   true,
+		},
+		{
+			name:      "binary unmarshaler rather than slice",
+			fieldType: reflect.TypeOf(binaryOnly{}),
+			input:     "a,b",
+			want:      binaryOnly("a,b"),
+		},
+		{
+			name:      "slice of text unmarshalers",
+			fieldType: reflect.TypeOf([]slog.Level{}),
+			input:     "DEBUG,ERROR",
+			want:      []slog.Level{slog.LevelDebug, slog.LevelError},
+		},
+	}
+
+	registry := NewTypeRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := readpipeline.New(tt.fieldType, "", registry)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			got, err := proc(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Process() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// upperName is a named string type that decodes itself.
+type upperName string
+
+func (n *upperName) UnmarshalText(text []byte) error {
+	*n = upperName(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestUnmarshalerTypes_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType reflect.Type
+		tags      reflect.StructTag
+		input     string
+		wantErr   string
+	}{
+		{"slog.Level within range", reflect.TypeOf(slog.Level(0)), `min:"0" max:"4"`, "WARN", ""},
+		{"slog.Level above max", reflect.TypeOf(slog.Level(0)), `min:"0" max:"4"`, "ERROR", "must be between 0 and 4"},
+		{"slog.Level below min", reflect.TypeOf(slog.Level(0)), `min:"0"`, "DEBUG", "below minimum 0"},
+		{"named string pattern applies to decoded value", reflect.TypeOf(upperName("")), `pattern:"^[A-Z]+$"`, "abc", ""},
+		{"named string pattern fails", reflect.TypeOf(upperName("")), `pattern:"^[A-Z]+$"`, "a-b", "does not match pattern"},
+		{"slice elements", reflect.TypeOf([]slog.Level{}), `max:"4"`, "INFO,ERROR", "item 1: above maximum 4"},
+	}
+
+	registry := NewTypeRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := readpipeline.New(tt.fieldType, tt.tags, registry)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			_, err = proc(tt.input)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Process() unexpected error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Process() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Kind handler comes from the registry", func(t *testing.T) {
+		local := NewTypeRegistry()
+		local.RegisterType(reflect.TypeFor[int](), readpipeline.WrapTypedHandler[int](&typeHandlerImpl[int]{
+			Parser: func(rawValue string) (int, error) {
+				return 0, errors.New("local handler")
+			},
+		}))
+		proc, err := readpipeline.New(reflect.TypeOf(slog.Level(0)), `max:"4"`, local)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if _, err := proc("WARN"); err == nil || !strings.Contains(err.Error(), "local handler") {
+			t.Errorf("Expected the local int handler to validate, got %v", err)
+		}
+	})
+
+	t.Run("Unsupported tags are rejected", func(t *testing.T) {
+		for _, tags := range []reflect.StructTag{`min:"2025-01-01T00:00:00Z"`, `pattern:"^1"`} {
+			if _, err := readpipeline.New(reflect.TypeOf(time.Time{}), tags, registry); err == nil {
+				t.Errorf("Expected error for tags %s", tags)
+			}
+		}
+	})
+}
+
+func TestUnmarshalerTypes_ExplicitRegistrationWins(t *testing.T) {
+	registry := NewTypeRegistry()
+	registry.RegisterType(reflect.TypeOf(slog.Level(0)), readpipeline.WrapTypedHandler[slog.Level](&typeHandlerImpl[slog.Level]{
+		Parser: func(rawValue string) (slog.Level, error) {
+			return slog.LevelError, nil
+		},
+	}))
+
+	proc, err := readpipeline.New(reflect.TypeOf(slog.Level(0)), "", registry)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	got, err := proc("DEBUG")
+	if err != nil || got != slog.LevelError {
+		t.Errorf("Process() got = %v, %v", got, err)
+	}
+}
+
+func TestUnmarshalerTypes_HasTypeHandler(t *testing.T) {
+	registry := NewTypeRegistry()
+	if !registry.HasTypeHandler(reflect.TypeOf(time.Time{})) {
+		t.Error("expected time.Time to have a type handler")
+	}
+	if registry.HasTypeHandler(reflect.TypeOf(struct{ X int }{})) {
+		t.Error("expected plain struct to have no type handler")
+	}
+}
@@ -28,6 +28,11 @@ func (m *mockRegistry) HandlerFor(t reflect.Type) PipelineBuilder {
 	return m.handlers[t]
 }
 
+func (m *mockRegistry) HasTypeHandler(t reflect.Type) bool {
+	_, ok := m.handlers[t]
+	return ok
+}
+
 func TestNew(t *testing.T) {
 	t.Run("BareType", func(t *testing.T) {
 		registry := &mockRegistry{
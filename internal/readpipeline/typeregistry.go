@@ -13,6 +13,9 @@ type TypedHandlerFactory[T any] func(t reflect.Type) TypedHandler[T]
 type TypeRegistry interface {
 	RegisterType(t reflect.Type, handler PipelineBuilder)
 	HandlerFor(t reflect.Type) PipelineBuilder
+	// HasTypeHandler reports whether the type has a handler of its own, rather than one chosen by its kind.
+	// This allows struct types such as url.URL to be treated as values rather than nested configuration.
+	HasTypeHandler(t reflect.Type) bool
 }
 
 // typedHandlerAdapter adapts a TypedHandler[T] to a PipelineBuilder.
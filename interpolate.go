@@ -0,0 +1,195 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInterpolationCycle is reported when variable references form a cycle.
+	ErrInterpolationCycle = errors.New("cycle in variable references")
+	// ErrInterpolationSyntax is reported when a value contains a malformed variable reference.
+	ErrInterpolationSyntax = errors.New("malformed variable reference")
+)
+
+// interpolator expands variable references in values, resolving them through the KeyStore.
+//
+// The syntax follows the shell:
+//   - ${VAR} is replaced by the value of VAR, or the empty string if it is not set
+//   - ${VAR:-default} uses default if VAR is not set or is empty
+//   - ${VAR:?message} is an error if VAR is not set or is empty
+//   - $$ is a literal $. A $ not followed by { or $ is also kept as is
+//
+// Referenced values are expanded in turn, so cycles are detected and reported. Values of literal keys, which
+// belong to fields tagged interpolate:"false", are inserted as is so that passwords containing $ are not changed.
+type interpolator struct {
+	store   KeyStore
+	literal map[string]bool
+	// resolving is the chain of variables currently being expanded
+	resolving []string
+}
+
+// storeError wraps an error from the KeyStore so that it can be failed fast rather than reported against a field.
+type storeError struct {
+	err error
+}
+
+func (e *storeError) Error() string { return e.err.Error() }
+
+func (e *storeError) Unwrap() error { return e.err }
+
+func newInterpolator(store KeyStore, literal map[string]bool) *interpolator {
+	return &interpolator{store: store, literal: literal}
+}
+
+// literalKeys returns the keys of the fields tagged interpolate:"false".
+func literalKeys(fields []structField) map[string]bool {
+	literal := make(map[string]bool)
+	for _, f := range fields {
+		if f.field.Tag.Get("interpolate") == "false" {
+			for _, key := range f.keys.all() {
+				literal[key] = true
+			}
+		}
+	}
+	return literal
+}
+
+// expandValue expands the value read from the key, so that a cycle back to the key is reported starting from it.
+// The key is empty for default values.
+func (in *interpolator) expandValue(ctx context.Context, key string, value string) (string, error) {
+	if key != "" {
+		in.resolving = append(in.resolving, key)
+		defer func() { in.resolving = in.resolving[:len(in.resolving)-1] }()
+	}
+	return in.expand(ctx, value)
+}
+
+// expand replaces all variable references in the value.
+func (in *interpolator) expand(ctx context.Context, value string) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '$' || i+1 >= len(value) {
+			result.WriteByte(c)
+			continue
+		}
+
+		switch value[i+1] {
+		case '$':
+			result.WriteByte('$')
+			i++
+		case '{':
+			end, err := findClosingBrace(value, i+2)
+			if err != nil {
+				return "", err
+			}
+			expanded, err := in.expandReference(ctx, value[i+2:end])
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(expanded)
+			i = end
+		default:
+			result.WriteByte(c)
+		}
+	}
+	return result.String(), nil
+}
+
+// findClosingBrace finds the brace that closes a reference starting at start, allowing nested references
+// in default values.
+func findClosingBrace(value string, start int) (int, error) {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch {
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '$':
+			i++
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			depth++
+			i++
+		case value[i] == '}':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: missing closing brace", ErrInterpolationSyntax)
+}
+
+// expandReference resolves the contents of a ${...} reference.
+func (in *interpolator) expandReference(ctx context.Context, reference string) (string, error) {
+	name, operator, word := reference, "", ""
+	if idx := strings.Index(reference, ":"); idx >= 0 {
+		name = reference[:idx]
+		if idx+1 >= len(reference) || (reference[idx+1] != '-' && reference[idx+1] != '?') {
+			return "", fmt.Errorf("%w: unsupported operator after %s", ErrInterpolationSyntax, name)
+		}
+		operator, word = reference[idx:idx+2], reference[idx+2:]
+	}
+	if !isValidVariableName(name) {
+		return "", fmt.Errorf("%w: invalid variable name", ErrInterpolationSyntax)
+	}
+
+	value, present, err := in.lookup(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if present && value != "" {
+		return value, nil
+	}
+
+	switch operator {
+	case ":-":
+		return in.expand(ctx, word)
+	case ":?":
+		if word == "" {
+			word = "is not set"
+		}
+		return "", fmt.Errorf("%s: %s", name, word)
+	}
+	return value, nil
+}
+
+// lookup reads a variable from the KeyStore and expands it, detecting cycles.
+func (in *interpolator) lookup(ctx context.Context, name string) (string, bool, error) {
+	for i, resolving := range in.resolving {
+		if resolving == name {
+			chain := append(append([]string{}, in.resolving[i:]...), name)
+			return "", false, fmt.Errorf("%w: %s", ErrInterpolationCycle, strings.Join(chain, " -> "))
+		}
+	}
+
+	value, present, err := in.store(ctx, name)
+	if err != nil {
+		return "", false, &storeError{err: err}
+	}
+	if !present || in.literal[name] {
+		return value, present, nil
+	}
+
+	in.resolving = append(in.resolving, name)
+	defer func() { in.resolving = in.resolving[:len(in.resolving)-1] }()
+	value, err = in.expand(ctx, value)
+	return value, true, err
+}
+
+// isValidVariableName accepts environment variable names, plus dots and dashes for properties style keys.
+func isValidVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c == '_' || c == '.' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
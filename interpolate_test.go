@@ -0,0 +1,215 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInterpolator(t *testing.T) {
+	ctx := context.Background()
+	values := map[string]string{
+		"DB_USER":  "app",
+		"DB_HOST":  "db.local",
+		"EMPTY":    "",
+		"NESTED":   "${DB_USER}@${DB_HOST}",
+		"ESCAPED":  "pa$$word",
+		"CYCLE_A":  "${CYCLE_B}",
+		"CYCLE_B":  "x${CYCLE_A}",
+		"SELF":     "${SELF}",
+		"db.port":  "5433",
+		"BAD_NEST": "${MISSING:?must be set}",
+	}
+	storeErr := errors.New("store failure")
+	store := func(ctx context.Context, key string) (string, bool, error) {
+		if key == "FAILING" {
+			return "", false, storeErr
+		}
+		val, ok := values[key]
+		return val, ok, nil
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "no references", input: "plain", want: "plain"},
+		{name: "simple reference", input: "${DB_USER}", want: "app"},
+		{name: "embedded references", input: "postgres://${DB_USER}@${DB_HOST}/app", want: "postgres://app@db.local/app"},
+		{name: "unset reference is empty", input: "a${MISSING}b", want: "ab"},
+		{name: "default when unset", input: "${DB_PORT:-5432}", want: "5432"},
+		{name: "default when empty", input: "${EMPTY:-fallback}", want: "fallback"},
+		{name: "default not used when set", input: "${DB_USER:-other}", want: "app"},
+		{name: "default is interpolated", input: "${MISSING:-${DB_HOST}:5432}", want: "db.local:5432"},
+		{name: "empty default", input: "${MISSING:-}", want: ""},
+		{name: "dotted name", input: "${db.port}", want: "5433"},
+		{name: "referenced value is interpolated", input: "${NESTED}", want: "app@db.local"},
+		{name: "escaped dollar", input: "cost $$5", want: "cost $5"},
+		{name: "escaped reference", input: "$${DB_USER}", want: "${DB_USER}"},
+		{name: "escape in referenced value", input: "${ESCAPED}", want: "pa$word"},
+		{name: "lone dollar", input: "a$b$", want: "a$b$"},
+		{name: "error when unset", input: "${MISSING:?must be set}", wantErr: "MISSING: must be set"},
+		{name: "error without message", input: "${EMPTY:?}", wantErr: "EMPTY: is not set"},
+		{name: "error from referenced value", input: "${BAD_NEST}", wantErr: "MISSING: must be set"},
+		{name: "cycle", input: "${CYCLE_A}", wantErr: "CYCLE_A -> CYCLE_B -> CYCLE_A"},
+		{name: "self reference", input: "${SELF}", wantErr: "SELF -> SELF"},
+		{name: "unterminated", input: "${DB_USER", wantErr: "missing closing brace"},
+		{name: "invalid name", input: "${DB USER}", wantErr: "invalid variable name"},
+		{name: "empty name", input: "${}", wantErr: "invalid variable name"},
+		{name: "unsupported operator", input: "${DB_USER:=x}", wantErr: "unsupported operator"},
+		{name: "store failure", input: "${FAILING}", wantErr: "store failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newInterpolator(store, nil).expand(ctx, tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("cycle error wraps sentinel", func(t *testing.T) {
+		_, err := newInterpolator(store, nil).expand(ctx, "${CYCLE_A}")
+		if !errors.Is(err, ErrInterpolationCycle) {
+			t.Errorf("Expected ErrInterpolationCycle, got %v", err)
+		}
+	})
+
+	t.Run("store failure is distinguished", func(t *testing.T) {
+		_, err := newInterpolator(store, nil).expand(ctx, "${DB_USER}${FAILING}")
+		var se *storeError
+		if !errors.As(err, &se) || !errors.Is(err, storeErr) {
+			t.Errorf("Expected storeError wrapping the store failure, got %v", err)
+		}
+	})
+}
+
+func TestLoad_Interpolation(t *testing.T) {
+	ctx := context.Background()
+	values := map[string]string{
+		"DB_USER":      "app",
+		"DB_HOST":      "db.local",
+		"DATABASE_URL": "postgres://${DB_USER}@${DB_HOST}:${DB_PORT:-5432}/app",
+		"PASSWORD":     "pa${ss",
+		"APP_NAME":     "${DB_USER}",
+		"LOOP":         "${LOOP}",
+		"PING":         "${PONG}",
+		"PONG":         "${PING}",
+	}
+	store := func(ctx context.Context, key string) (string, bool, error) {
+		val, ok := values[key]
+		return val, ok, nil
+	}
+
+	type Config struct {
+		DatabaseURL string `key:"DATABASE_URL"`
+		DataDir     string `key:"DATA_DIR" default:"/var/${DB_USER}"`
+		Password    string `key:"PASSWORD" interpolate:"false"`
+		Name        string `key:"NAME" default:"${APP_NAME:?is required}"`
+	}
+
+	t.Run("expands values and defaults", func(t *testing.T) {
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(store), WithInterpolation()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.DatabaseURL != "postgres://app@db.local:5432/app" {
+			t.Errorf("DatabaseURL = %q", cfg.DatabaseURL)
+		}
+		if cfg.DataDir != "/var/app" {
+			t.Errorf("DataDir = %q", cfg.DataDir)
+		}
+		if cfg.Password != "pa${ss" {
+			t.Errorf("Password = %q", cfg.Password)
+		}
+		if cfg.Name != "app" {
+			t.Errorf("Name = %q", cfg.Name)
+		}
+	})
+
+	t.Run("referenced literal fields are not expanded", func(t *testing.T) {
+		values := map[string]string{"DB_PASSWORD": "p$$w${X}", "DATABASE_URL": "postgres://app:${DB_PASSWORD}@db"}
+		store := func(ctx context.Context, key string) (string, bool, error) {
+			val, ok := values[key]
+			return val, ok, nil
+		}
+		type Config struct {
+			DatabaseURL string `key:"DATABASE_URL"`
+			Password    string `key:"DB_PASSWORD" interpolate:"false"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(store), WithInterpolation()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.DatabaseURL != "postgres://app:p$$w${X}@db" || cfg.Password != "p$$w${X}" {
+			t.Errorf("Got %+v", cfg)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(store)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.DatabaseURL != values["DATABASE_URL"] {
+			t.Errorf("DatabaseURL = %q", cfg.DatabaseURL)
+		}
+	})
+
+	t.Run("errors are reported against the field", func(t *testing.T) {
+		type LoopConfig struct {
+			Loop    string `key:"LOOP"`
+			Missing string `key:"MISSING" default:"${NOPE:?must be set}"`
+		}
+		var cfg LoopConfig
+		err := Load(ctx, &cfg, WithKeyStore(store), WithInterpolation())
+		var configErrors *ConfigErrors
+		if !errors.As(err, &configErrors) || configErrors.Len() != 2 {
+			t.Fatalf("Expected two ConfigErrors, got %v", err)
+		}
+		if configErrors.Errors[0].Key != "LOOP" || !errors.Is(configErrors.Errors[0].Err, ErrInterpolationCycle) {
+			t.Errorf("Unexpected first error %v", configErrors.Errors[0])
+		}
+		if configErrors.Errors[1].Key != "MISSING" {
+			t.Errorf("Unexpected second error %v", configErrors.Errors[1])
+		}
+	})
+
+	t.Run("cycles start at the field's key", func(t *testing.T) {
+		type PingConfig struct {
+			Ping string `key:"PING"`
+		}
+		var cfg PingConfig
+		err := Load(ctx, &cfg, WithKeyStore(store), WithInterpolation())
+		if err == nil || !strings.Contains(err.Error(), "PING -> PONG -> PING") {
+			t.Errorf("Expected cycle starting at PING, got %v", err)
+		}
+	})
+
+	t.Run("store failures fail fast", func(t *testing.T) {
+		storeErr := errors.New("store failure")
+		failing := func(ctx context.Context, key string) (string, bool, error) {
+			if key == "DB_USER" {
+				return "", false, storeErr
+			}
+			return store(ctx, key)
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(failing), WithInterpolation()); !errors.Is(err, storeErr) {
+			t.Errorf("Expected store failure, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package goconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NewJSONKeyStore reads a JSON document and returns a KeyStore for its values. Nested objects are flattened so that
+// {"database":{"port":5432}} is named database.port, and by default mapped with EnvStyleKeys to DATABASE_PORT.
+// Use WithKeyMapping to change this.
+//
+// Arrays are served as JSON, which slice fields tagged format:"json" accept. Objects are also served
+// whole as JSON so that map and struct fields with their own key can read them. Null values are not present.
+func NewJSONKeyStore(filename string, options ...FileStoreOption) (KeyStore, error) {
+	return loadFileKeyStore(filename, parseJSONDocument, EnvStyleKeys, options)
+}
+
+// parseJSONDocument parses a JSON object and flattens it.
+func parseJSONDocument(filename string, content string) ([]fileEntry, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, jsonSyntaxError(filename, content, err)
+	}
+	end := int(decoder.InputOffset())
+	if _, err := decoder.Token(); err != io.EOF {
+		trailing := len(content[end:]) - len(strings.TrimLeft(content[end:], " \t\r\n"))
+		line, column := offsetPosition(content, end+trailing)
+		return nil, &SyntaxError{File: filename, Line: line, Column: column, Msg: "unexpected data after the document"}
+	}
+
+	object, ok := document.(map[string]any)
+	if !ok {
+		return nil, &SyntaxError{File: filename, Line: 1, Msg: "document must be an object"}
+	}
+	return flattenDocument(object)
+}
+
+// jsonSyntaxError converts a decoding error into a SyntaxError. The decoder's messages can quote the content,
+// so they are replaced with a general description.
+func jsonSyntaxError(filename, content string, err error) error {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, column := offsetPosition(content, int(syntaxErr.Offset)-1)
+		return &SyntaxError{File: filename, Line: line, Column: column, Msg: "invalid JSON"}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		line, column := offsetPosition(content, len(content))
+		return &SyntaxError{File: filename, Line: line, Column: column, Msg: "unexpected end of JSON input"}
+	}
+	return err
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column.
+func offsetPosition(content string, offset int) (int, int) {
+	offset = max(0, min(offset, len(content)))
+	before := content[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := offset - strings.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// flattenDocument flattens a decoded document into entries named by their dotted path. The document may contain
+// maps, slices, strings, json.Number, bools and nil.
+func flattenDocument(document map[string]any) ([]fileEntry, error) {
+	var entries []fileEntry
+	if err := flattenValue("", document, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func flattenValue(path string, value any, entries *[]fileEntry) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		if path != "" {
+			if err := addJSONEntry(path, v, entries); err != nil {
+				return err
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			childPath := name
+			if path != "" {
+				childPath = path + "." + name
+			}
+			if err := flattenValue(childPath, v[name], entries); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		return addJSONEntry(path, v, entries)
+	case string:
+		*entries = append(*entries, fileEntry{Key: path, Value: v})
+	case json.Number:
+		*entries = append(*entries, fileEntry{Key: path, Value: v.String()})
+	case bool:
+		*entries = append(*entries, fileEntry{Key: path, Value: strconv.FormatBool(v)})
+	default:
+		return errors.New("unsupported value in document at " + path)
+	}
+	return nil
+}
+
+// addJSONEntry serializes an array or object as the value for the path.
+func addJSONEntry(path string, value any, entries *[]fileEntry) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return errors.New("cannot serialize value in document at " + path)
+	}
+	*entries = append(*entries, fileEntry{Key: path, Value: strings.TrimSuffix(buffer.String(), "\n")})
+	return nil
+}
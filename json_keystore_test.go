@@ -0,0 +1,123 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []fileEntry
+		wantErr *SyntaxError
+	}{
+		{
+			name:  "nested objects",
+			input: `{"database": {"port": 5432, "host": "localhost", "ssl": true, "timeout": null}, "name": "app"}`,
+			want: []fileEntry{
+				{Key: "database", Value: `{"host":"localhost","port":5432,"ssl":true,"timeout":null}`},
+				{Key: "database.host", Value: "localhost"},
+				{Key: "database.port", Value: "5432"},
+				{Key: "database.ssl", Value: "true"},
+				{Key: "name", Value: "app"},
+			},
+		},
+		{
+			name:  "arrays are not flattened",
+			input: `{"hosts": ["a", "b"], "limits": [{"n": 1.50}], "html": ["<&>"]}`,
+			want: []fileEntry{
+				{Key: "hosts", Value: `["a","b"]`},
+				{Key: "html", Value: `["<&>"]`},
+				{Key: "limits", Value: `[{"n":1.50}]`},
+			},
+		},
+		{
+			name:  "numbers keep their form",
+			input: `{"big": 12345678901234567890, "float": 1e-7}`,
+			want: []fileEntry{
+				{Key: "big", Value: "12345678901234567890"},
+				{Key: "float", Value: "1e-7"},
+			},
+		},
+		{
+			name:    "syntax error",
+			input:   "{\n  \"a\": 1,\n  \"b\" 2\n}",
+			wantErr: &SyntaxError{File: "test.json", Line: 3, Column: 7, Msg: "invalid JSON"},
+		},
+		{
+			name:    "truncated",
+			input:   "{\"a\": ",
+			wantErr: &SyntaxError{File: "test.json", Line: 1, Column: 7, Msg: "unexpected end of JSON input"},
+		},
+		{
+			name:    "not an object",
+			input:   "[1, 2]",
+			wantErr: &SyntaxError{File: "test.json", Line: 1, Msg: "document must be an object"},
+		},
+		{
+			name:    "trailing data",
+			input:   "{}\n{}",
+			wantErr: &SyntaxError{File: "test.json", Line: 2, Column: 1, Msg: "unexpected data after the document"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJSONDocument("test.json", tt.input)
+			if tt.wantErr != nil {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) || *syntaxErr != *tt.wantErr {
+					t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewJSONKeyStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+		"database": {"port": 5432, "maxConns": 10, "labels": {"team": "core"}},
+		"allowedHosts": ["a.example.com", "b.example.com"]
+	}`
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewJSONKeyStore(filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	type Config struct {
+		Port         int               `key:"DATABASE_PORT"`
+		MaxConns     int               `key:"DATABASE_MAX_CONNS"`
+		Labels       map[string]string `key:"DATABASE_LABELS"`
+		AllowedHosts []string          `key:"ALLOWED_HOSTS" format:"json"`
+	}
+	var cfg Config
+	if err := Load(context.Background(), &cfg, WithKeyStore(store)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := Config{
+		Port:         5432,
+		MaxConns:     10,
+		Labels:       map[string]string{"team": "core"},
+		AllowedHosts: []string{"a.example.com", "b.example.com"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Got %+v, want %+v", cfg, want)
+	}
+}
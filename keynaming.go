@@ -0,0 +1,68 @@
+package goconfig
+
+import (
+	"strings"
+	"unicode"
+)
+
+// KeyNaming derives a key from the Go field names leading to a field, for example ["Database", "MaxConns"].
+// The names start below the nearest struct with a prefix tag, so the prefix takes the place of the outer names.
+type KeyNaming func(fieldNames []string) string
+
+// SnakeCaseKeys derives environment variable style keys, for example Database.MaxConns becomes DATABASE_MAX_CONNS.
+func SnakeCaseKeys(fieldNames []string) string {
+	return strings.ToUpper(joinWords(fieldNames, "_"))
+}
+
+// DotCaseKeys derives properties file style keys, for example Database.MaxConns becomes database.max.conns.
+func DotCaseKeys(fieldNames []string) string {
+	return strings.ToLower(joinWords(fieldNames, "."))
+}
+
+// KebabCaseKeys derives keys in kebab case, for example Database.MaxConns becomes database-max-conns.
+func KebabCaseKeys(fieldNames []string) string {
+	return strings.ToLower(joinWords(fieldNames, "-"))
+}
+
+// joinWords splits each field name into words and joins all the words with the separator.
+func joinWords(fieldNames []string, separator string) string {
+	var words []string
+	for _, name := range fieldNames {
+		words = append(words, splitWords(name)...)
+	}
+	return strings.Join(words, separator)
+}
+
+// splitWords splits a Go identifier into words. Runs of capitals are treated as acronyms, so HTTPServer becomes
+// HTTP, Server and UserID becomes User, ID. Digits stay with the word before them. Underscores also separate words.
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, curr := runes[i-1], runes[i]
+		boundary := false
+		switch {
+		case curr == '_':
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		case unicode.IsUpper(curr) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			// lower to upper: maxConns
+			boundary = true
+		case unicode.IsUpper(curr) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// end of an acronym: HTTPServer
+			boundary = true
+		}
+		if boundary && i > start {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,121 @@
+package goconfig
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Port", []string{"Port"}},
+		{"MaxConns", []string{"Max", "Conns"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"APIKey", []string{"API", "Key"}},
+		{"UserID", []string{"User", "ID"}},
+		{"ID", []string{"ID"}},
+		{"Server2Port", []string{"Server2", "Port"}},
+		{"TLS_Cert", []string{"TLS", "Cert"}},
+		{"maxConns", []string{"max", "Conns"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitWords(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitWords(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyNamingStrategies(t *testing.T) {
+	path := []string{"Database", "MaxConns"}
+	if got := SnakeCaseKeys(path); got != "DATABASE_MAX_CONNS" {
+		t.Errorf("SnakeCaseKeys = %q", got)
+	}
+	if got := DotCaseKeys(path); got != "database.max.conns" {
+		t.Errorf("DotCaseKeys = %q", got)
+	}
+	if got := KebabCaseKeys(path); got != "database-max-conns" {
+		t.Errorf("KebabCaseKeys = %q", got)
+	}
+}
+
+func TestLoad_KeyNaming(t *testing.T) {
+	type Database struct {
+		Host     string
+		MaxConns int    `default:"10"`
+		Password string `key:"DB_PASSWORD"`
+	}
+	type Common struct {
+		LogLevel string
+	}
+	type Config struct {
+		Common
+		APIKey   string
+		Internal string `key:"-"`
+		Database Database
+		Replica  *Database `prefix:"REPLICA_"`
+		BaseURL  *url.URL
+	}
+
+	values := map[string]string{
+		"LOG_LEVEL":          "debug",
+		"API_KEY":            "sk-123",
+		"INTERNAL":           "should not be read",
+		"DATABASE_HOST":      "primary",
+		"DATABASE_MAX_CONNS": "20",
+		"DB_PASSWORD":        "secret",
+		"REPLICA_HOST":       "replica",
+		"BASE_URL":           "https://example.com/",
+	}
+	var requested []string
+	mockStore := func(ctx context.Context, key string) (string, bool, error) {
+		requested = append(requested, key)
+		val, ok := values[key]
+		return val, ok, nil
+	}
+
+	var cfg Config
+	if err := Load(context.Background(), &cfg, WithKeyStore(mockStore), WithKeyNaming(SnakeCaseKeys)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	wantRequested := []string{
+		"LOG_LEVEL", "API_KEY", "DATABASE_HOST", "DATABASE_MAX_CONNS", "DB_PASSWORD",
+		"REPLICA_HOST", "REPLICA_MAX_CONNS", "REPLICA_DB_PASSWORD", "BASE_URL",
+	}
+	if !reflect.DeepEqual(requested, wantRequested) {
+		t.Errorf("Requested keys %v, want %v", requested, wantRequested)
+	}
+	if cfg.LogLevel != "debug" || cfg.APIKey != "sk-123" || cfg.Internal != "" {
+		t.Errorf("Unexpected top level values: %+v", cfg)
+	}
+	if cfg.Database.Host != "primary" || cfg.Database.MaxConns != 20 || cfg.Database.Password != "secret" {
+		t.Errorf("Unexpected Database: %+v", cfg.Database)
+	}
+	if cfg.Replica.Host != "replica" || cfg.Replica.MaxConns != 10 {
+		t.Errorf("Unexpected Replica: %+v", cfg.Replica)
+	}
+	if cfg.BaseURL == nil || cfg.BaseURL.Host != "example.com" {
+		t.Errorf("Unexpected BaseURL: %v", cfg.BaseURL)
+	}
+
+	t.Run("Without key naming untagged fields are skipped", func(t *testing.T) {
+		requested = nil
+		var cfg Config
+		if err := Load(context.Background(), &cfg, WithKeyStore(mockStore)); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if !reflect.DeepEqual(requested, []string{"DB_PASSWORD", "REPLICA_DB_PASSWORD"}) {
+			t.Errorf("Requested keys %v", requested)
+		}
+		if cfg.BaseURL != nil {
+			t.Errorf("Expected BaseURL to be left alone, got %v", cfg.BaseURL)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrKeyringUnsupported is returned by the keyring KeyStore on platforms other than Linux.
+var ErrKeyringUnsupported = errors.New("kernel keyring is only supported on Linux")
+
+// Keyring identifies a Linux kernel keyring, either by one of the special keyring constants or by serial number.
+type Keyring int32
+
+// The special keyrings, as in keyctl(2).
+const (
+	ProcessKeyring     Keyring = -2
+	SessionKeyring     Keyring = -3
+	UserKeyring        Keyring = -4
+	UserSessionKeyring Keyring = -5
+)
+
+// KeyringOption configures NewKeyringKeyStore.
+type KeyringOption func(*keyringSettings)
+
+type keyringSettings struct {
+	keyrings    []Keyring
+	description func(key string) string
+}
+
+// WithKeyrings sets the keyrings to search, in order. The default is the session keyring followed by the user
+// keyring. Keyrings linked into a searched keyring are searched too.
+func WithKeyrings(keyrings ...Keyring) KeyringOption {
+	return func(s *keyringSettings) { s.keyrings = keyrings }
+}
+
+// WithKeyDescription sets how a key is mapped to the description of a key in the keyring, for example to add an
+// application prefix. The default uses the key as the description.
+func WithKeyDescription(mapping func(key string) string) KeyringOption {
+	return func(s *keyringSettings) { s.description = mapping }
+}
+
+// NewKeyringKeyStore returns a KeyStore that reads "user" type keys from the Linux kernel keyring, using the
+// keyctl system calls. Secrets held in the keyring never touch disk or the process environment:
+//
+//	$ keyctl padd user myapp:DB_PASSWORD @s < password.txt
+//
+//	store := goconfig.NewKeyringKeyStore(goconfig.WithKeyDescription(func(key string) string {
+//	    return "myapp:" + key
+//	}))
+//
+// Keys that do not exist, have been revoked or have expired are not present. Other failures, such as permission
+// denied, are returned as a ConfigError. On other platforms every lookup returns ErrKeyringUnsupported.
+func NewKeyringKeyStore(options ...KeyringOption) KeyStore {
+	settings := &keyringSettings{
+		keyrings:    []Keyring{SessionKeyring, UserKeyring},
+		description: func(key string) string { return key },
+	}
+	for _, opt := range options {
+		opt(settings)
+	}
+
+	return func(ctx context.Context, key string) (string, bool, error) {
+		description := settings.description(key)
+		if description == "" {
+			return "", false, nil
+		}
+		for _, keyring := range settings.keyrings {
+			value, ok, err := readKeyringKey(keyring, description)
+			if err != nil {
+				return "", false, ConfigError{Key: key, Err: fmt.Errorf("reading keyring: %w", err)}
+			}
+			if ok {
+				return value, true, nil
+			}
+		}
+		return "", false, nil
+	}
+}
@@ -0,0 +1,76 @@
+//go:build linux
+
+package goconfig
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+// keyctl operations, from linux/keyctl.h
+const (
+	keyctlSearch = 10
+	keyctlRead   = 11
+)
+
+// readKeyringKey searches the keyring for a user key with the description and reads its payload.
+func readKeyringKey(keyring Keyring, description string) (string, bool, error) {
+	id, err := searchKeyring(keyring, description)
+	if isAbsentKeyError(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	// The size can change between calls if the key is updated, so read until the buffer is large enough
+	var buffer []byte
+	for {
+		size, err := syscallResult(syscall.Syscall6(syscall.SYS_KEYCTL, keyctlRead, uintptr(id),
+			uintptr(unsafe.Pointer(unsafe.SliceData(buffer))), uintptr(len(buffer)), 0, 0))
+		if isAbsentKeyError(err) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if int(size) <= len(buffer) {
+			return string(buffer[:size]), true, nil
+		}
+		buffer = make([]byte, size)
+	}
+}
+
+// searchKeyring returns the serial number of the user key with the description, searching linked keyrings too.
+func searchKeyring(keyring Keyring, description string) (int32, error) {
+	keyType, err := syscall.BytePtrFromString("user")
+	if err != nil {
+		return 0, err
+	}
+	desc, err := syscall.BytePtrFromString(description)
+	if err != nil {
+		return 0, err
+	}
+	id, err := syscallResult(syscall.Syscall6(syscall.SYS_KEYCTL, keyctlSearch, keyring.arg(),
+		uintptr(unsafe.Pointer(keyType)), uintptr(unsafe.Pointer(desc)), 0, 0))
+	return int32(id), err
+}
+
+// isAbsentKeyError reports whether the error means that the key is not available to read.
+func isAbsentKeyError(err error) bool {
+	return errors.Is(err, syscall.ENOKEY) || errors.Is(err, syscall.EKEYREVOKED) || errors.Is(err, syscall.EKEYEXPIRED)
+}
+
+// syscallResult converts the results of syscall.Syscall6 to a value and error.
+func syscallResult(result, _ uintptr, errno syscall.Errno) (uintptr, error) {
+	if errno != 0 {
+		return 0, errno
+	}
+	return result, nil
+}
+
+// arg converts the keyring to a system call argument. Negative values are sign extended, which the kernel accepts.
+func (k Keyring) arg() uintptr {
+	return uintptr(k)
+}
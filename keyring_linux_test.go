@@ -0,0 +1,165 @@
+//go:build linux
+
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+// keyctl operations used by the tests, from linux/keyctl.h
+const (
+	keyctlRevoke = 3
+	keyctlUnlink = 9
+)
+
+// testKeyring is a throwaway keyring linked into the session keyring, so it is searched by the default store, until
+// close revokes it.
+type testKeyring struct {
+	// ID is the keyring's serial number, for use with WithKeyrings
+	ID Keyring
+}
+
+// newTestKeyring creates a throwaway keyring, skipping the test where keyrings are not available.
+func newTestKeyring(t *testing.T) *testKeyring {
+	t.Helper()
+	id, err := addKey("keyring", fmt.Sprintf("goconfig-test-%d", os.Getpid()), nil, SessionKeyring)
+	if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.ENOSYS) || errors.Is(err, syscall.EACCES) {
+		t.Skipf("kernel keyring not available: %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := &testKeyring{ID: Keyring(id)}
+	t.Cleanup(func() { _ = keyring.close() })
+	return keyring
+}
+
+// add adds a user key to the keyring, replacing any key with the same description. The kernel requires a value of
+// 1 to 32767 bytes.
+func (k *testKeyring) add(description, value string) error {
+	_, err := addKey("user", description, []byte(value), k.ID)
+	return err
+}
+
+// revoke revokes the key with the description, so that it can no longer be read.
+func (k *testKeyring) revoke(description string) error {
+	id, err := searchKeyring(k.ID, description)
+	if err != nil {
+		return err
+	}
+	_, err = keyctl(keyctlRevoke, uintptr(id), 0)
+	return err
+}
+
+// close revokes the keyring and unlinks it from the session keyring.
+func (k *testKeyring) close() error {
+	_, revokeErr := keyctl(keyctlRevoke, k.ID.arg(), 0)
+	_, unlinkErr := keyctl(keyctlUnlink, k.ID.arg(), SessionKeyring.arg())
+	return errors.Join(revokeErr, unlinkErr)
+}
+
+// addKey adds or updates a key in the keyring, returning its serial number.
+func addKey(keyType, description string, payload []byte, keyring Keyring) (int32, error) {
+	typePtr, err := syscall.BytePtrFromString(keyType)
+	if err != nil {
+		return 0, err
+	}
+	descPtr, err := syscall.BytePtrFromString(description)
+	if err != nil {
+		return 0, err
+	}
+	id, err := syscallResult(syscall.Syscall6(syscall.SYS_ADD_KEY, uintptr(unsafe.Pointer(typePtr)),
+		uintptr(unsafe.Pointer(descPtr)), uintptr(unsafe.Pointer(unsafe.SliceData(payload))), uintptr(len(payload)),
+		keyring.arg(), 0))
+	return int32(id), err
+}
+
+// keyctl makes a keyctl call whose arguments are all integers. Calls passing pointers must convert them to uintptr
+// within the call to syscall.Syscall6, so that the memory they point to is kept alive and in place.
+func keyctl(operation, arg2, arg3 uintptr) (uintptr, error) {
+	return syscallResult(syscall.Syscall6(syscall.SYS_KEYCTL, operation, arg2, arg3, 0, 0, 0))
+}
+
+func TestKeyringKeyStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Reads keys", func(t *testing.T) {
+		keyring := newTestKeyring(t)
+		if err := keyring.add("DB_PASSWORD", "s3cret"); err != nil {
+			t.Fatal(err)
+		}
+		store := NewKeyringKeyStore(WithKeyrings(keyring.ID))
+
+		tests := []struct {
+			key     string
+			want    string
+			present bool
+		}{
+			{"DB_PASSWORD", "s3cret", true},
+			{"MISSING", "", false},
+		}
+		for _, tt := range tests {
+			value, ok, err := store(ctx, tt.key)
+			if err != nil || ok != tt.present || value != tt.want {
+				t.Errorf("store(%s) = (%q, %v, %v), want (%q, %v)", tt.key, value, ok, err, tt.want, tt.present)
+			}
+		}
+	})
+
+	t.Run("Updated and revoked keys", func(t *testing.T) {
+		keyring := newTestKeyring(t)
+		store := NewKeyringKeyStore(WithKeyrings(keyring.ID))
+		if err := keyring.add("TOKEN", "first"); err != nil {
+			t.Fatal(err)
+		}
+		if err := keyring.add("TOKEN", "a much longer second value"); err != nil {
+			t.Fatal(err)
+		}
+		if value, _, _ := store(ctx, "TOKEN"); value != "a much longer second value" {
+			t.Errorf("Expected the updated value, got %q", value)
+		}
+
+		if err := keyring.revoke("TOKEN"); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, err := store(ctx, "TOKEN"); ok || err != nil {
+			t.Errorf("Expected revoked key to be absent, got (%v, %v)", ok, err)
+		}
+	})
+
+	t.Run("Default keyrings and description mapping", func(t *testing.T) {
+		keyring := newTestKeyring(t)
+		description := fmt.Sprintf("goconfig-test-%d:API_KEY", os.Getpid())
+		if err := keyring.add(description, "abc123"); err != nil {
+			t.Fatal(err)
+		}
+		store := NewKeyringKeyStore(WithKeyDescription(func(key string) string {
+			return fmt.Sprintf("goconfig-test-%d:%s", os.Getpid(), key)
+		}))
+
+		type Config struct {
+			APIKey string `key:"API_KEY"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(store)); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.APIKey != "abc123" {
+			t.Errorf("Got %+v", cfg)
+		}
+	})
+}
+
+func TestIsTransientError_KeyringErrors(t *testing.T) {
+	for _, err := range []error{syscall.EKEYREVOKED, syscall.EKEYEXPIRED, fmt.Errorf("reading keyring: %w", syscall.EKEYREVOKED)} {
+		if IsTransientError(err) {
+			t.Errorf("Expected %v not to be transient", err)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package goconfig
+
+// isAbsentKeyError reports whether the error means that a keyring key is not available, which only happens on Linux.
+func isAbsentKeyError(err error) bool {
+	return false
+}
+
+func readKeyringKey(keyring Keyring, description string) (string, bool, error) {
+	return "", false, ErrKeyringUnsupported
+}
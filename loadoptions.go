@@ -1,6 +1,7 @@
 package goconfig
 
 import (
+	"log/slog"
 	"reflect"
 
 	"github.com/m0rjc/goconfig/internal/builtintypes"
@@ -28,12 +29,76 @@ func WithCustomType[T any](handler TypedHandler[T]) Option {
 	}
 }
 
+// WithKeyPrefix prepends a prefix, such as "MYAPP_", to every key. It composes with prefix tags on nested structs.
+func WithKeyPrefix(prefix string) Option {
+	return func(opts *loadOptions) {
+		opts.keyPrefix = prefix
+	}
+}
+
+// WithKeyNaming derives keys for fields that do not have a key tag, using the given strategy such as SnakeCaseKeys.
+// Explicit key tags still take precedence, and a key of "-" excludes a field.
+func WithKeyNaming(strategy KeyNaming) Option {
+	return func(opts *loadOptions) {
+		opts.keyNaming = strategy
+	}
+}
+
+// WithLogger sets the logger used for warnings during Load, such as the use of deprecated keys.
+// The default is slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(opts *loadOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithInterpolation expands references to other keys in values and default tags, for example
+// "postgres://${DB_USER}@${DB_HOST}:${DB_PORT:-5432}/app". References are resolved through the KeyStore
+// without the key prefix. Use $$ for a literal $, or the tag interpolate:"false" to leave a field's value as is,
+// including where other values refer to it.
+func WithInterpolation() Option {
+	return func(opts *loadOptions) {
+		opts.interpolate = true
+	}
+}
+
+// WithValidator adds a validator for the field at the given path, for example "Database.Port".
+// The path is made of Go field names, not keys. The validator receives the parsed value with the field's type
+// (unboxed if the field is a pointer) and runs after any tag-based validation.
+func WithValidator(path string, validator Validator[any]) Option {
+	return func(opts *loadOptions) {
+		opts.validators[path] = append(opts.validators[path], validator)
+	}
+}
+
+// WithValidatorFactory registers a factory that is consulted for every field with a key. The factory can inspect
+// the field's tags or type and return validators to add to the field's pipeline.
+func WithValidatorFactory(factory ValidatorFactory) Option {
+	return func(opts *loadOptions) {
+		opts.validatorFactories = append(opts.validatorFactories, factory)
+	}
+}
+
 // loadOptions holds the configuration options for Load.
 type loadOptions struct {
 	// keyStore reads the values. Default to os.GetEnv()
 	keyStore KeyStore
 	// typeRegistry holds the handlers for specific types
 	typeRegistry readpipeline.TypeRegistry
+	// validators holds the custom validators keyed on field path
+	validators map[string][]Validator[any]
+	// validatorFactories contribute validators to every field
+	validatorFactories []ValidatorFactory
+	// keyPrefix is prepended to every key
+	keyPrefix string
+	// keyNaming derives keys for fields without a key tag. If nil then such fields are skipped
+	keyNaming KeyNaming
+	// logger receives warnings
+	logger *slog.Logger
+	// interpolate enables expansion of ${VAR} references in values
+	interpolate bool
+	// literalKeys are the keys of fields tagged interpolate:"false", whose values are referenced as is
+	literalKeys map[string]bool
 }
 
 // newLoadOptions creates default load options.
@@ -41,6 +106,8 @@ func newLoadOptions() *loadOptions {
 	return &loadOptions{
 		keyStore:     EnvironmentKeyStore,
 		typeRegistry: builtintypes.NewTypeRegistry(),
+		validators:   make(map[string][]Validator[any]),
+		logger:       slog.Default(),
 	}
 }
 
@@ -0,0 +1,104 @@
+package goconfig
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// loadSession holds state shared by the KeyStores during a single call to Load.
+type loadSession struct {
+	// keys are the keys that Load may ask for, so that stores can fetch them in bulk
+	keys []string
+
+	mu    sync.Mutex
+	cache map[any]*sessionEntry
+}
+
+// sessionEntry holds the result of a fetch. Its mutex is held during the fetch so that concurrent lookups wait
+// for it rather than fetching again.
+type sessionEntry struct {
+	mu      sync.Mutex
+	fetched bool
+	value   any
+}
+
+type loadSessionKey struct{}
+
+// withLoadSession returns a context carrying a new session for the given keys.
+func withLoadSession(ctx context.Context, keys []string) context.Context {
+	return context.WithValue(ctx, loadSessionKey{}, &loadSession{keys: keys, cache: make(map[any]*sessionEntry)})
+}
+
+// RequestedKeys returns the keys that the current Load may ask for, including aliases and deprecated keys, so that
+// a KeyStore can fetch them in a single request. It returns nil if the context does not come from Load.
+// Load may still ask for other keys, for example to resolve interpolated values.
+func RequestedKeys(ctx context.Context) []string {
+	if session, ok := ctx.Value(loadSessionKey{}).(*loadSession); ok {
+		return append([]string{}, session.keys...)
+	}
+	return nil
+}
+
+// fetchOncePerLoad calls fetch once for each cache key during a Load, sharing the result between lookups.
+// The cache key should include the store, for example a struct holding the store's pointer and a path.
+// Errors are not kept, so the next lookup fetches again, which allows NewRetryKeyStore to retry within a Load.
+// Outside of Load, fetch is called every time.
+func fetchOncePerLoad(ctx context.Context, cacheKey any, fetch func() (any, error)) (any, error) {
+	session, ok := ctx.Value(loadSessionKey{}).(*loadSession)
+	if !ok {
+		return fetch()
+	}
+
+	session.mu.Lock()
+	entry, exists := session.cache[cacheKey]
+	if !exists {
+		entry = &sessionEntry{}
+		session.cache[cacheKey] = entry
+	}
+	session.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.fetched {
+		return entry.value, nil
+	}
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	entry.value, entry.fetched = value, true
+	return value, nil
+}
+
+// keyedFields walks the config struct in the same way as Load to list the fields that it will read, including
+// those in nested structs.
+func keyedFields(v reflect.Value, scope structScope, opts *loadOptions) ([]structField, error) {
+	fields, err := structFields(v, scope, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyed []structField
+	for _, f := range fields {
+		if f.nested {
+			nestedFields, err := keyedFields(f.value, f.scope, opts)
+			if err != nil {
+				return nil, err
+			}
+			keyed = append(keyed, nestedFields...)
+			continue
+		}
+		keyed = append(keyed, f)
+	}
+	return keyed, nil
+}
+
+// requestedKeys lists the keys that Load will ask for to read the fields.
+func requestedKeys(fields []structField) []string {
+	var keys []string
+	for _, f := range fields {
+		keys = append(keys, f.keys.all()...)
+	}
+	return keys
+}
@@ -0,0 +1,84 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLoadSession(t *testing.T) {
+	ctx := context.Background()
+
+	type Database struct {
+		Host string `key:"HOST"`
+	}
+	type Config struct {
+		Port     int      `key:"PORT,HTTP_PORT" deprecated:"OLD_PORT"`
+		Database Database `prefix:"DB_"`
+		Skipped  string   `key:"-"`
+	}
+
+	t.Run("Stores see the requested keys", func(t *testing.T) {
+		var seen []string
+		store := func(ctx context.Context, key string) (string, bool, error) {
+			if seen == nil {
+				seen = RequestedKeys(ctx)
+			}
+			return "", false, nil
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(store)); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		want := []string{"PORT", "HTTP_PORT", "OLD_PORT", "DB_HOST"}
+		if !slices.Equal(seen, want) {
+			t.Errorf("RequestedKeys() = %v, want %v", seen, want)
+		}
+	})
+
+	t.Run("Outside Load", func(t *testing.T) {
+		if keys := RequestedKeys(ctx); keys != nil {
+			t.Errorf("Expected nil, got %v", keys)
+		}
+		calls := 0
+		fetch := func() (any, error) { calls++; return calls, nil }
+		_, _ = fetchOncePerLoad(ctx, "key", fetch)
+		_, _ = fetchOncePerLoad(ctx, "key", fetch)
+		if calls != 2 {
+			t.Errorf("Expected fetch on every call, got %d", calls)
+		}
+	})
+
+	t.Run("Fetches once per session", func(t *testing.T) {
+		session := withLoadSession(ctx, nil)
+		calls := 0
+		fetch := func() (any, error) { calls++; return calls, nil }
+		first, _ := fetchOncePerLoad(session, "key", fetch)
+		second, _ := fetchOncePerLoad(session, "key", fetch)
+		_, _ = fetchOncePerLoad(session, "other", fetch)
+		if first != 1 || second != 1 || calls != 2 {
+			t.Errorf("Got %v, %v after %d calls", first, second, calls)
+		}
+	})
+
+	t.Run("Errors are not kept", func(t *testing.T) {
+		session := withLoadSession(ctx, nil)
+		calls := 0
+		fetch := func() (any, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("unavailable")
+			}
+			return calls, nil
+		}
+		if _, err := fetchOncePerLoad(session, "key", fetch); err == nil {
+			t.Fatal("Expected the first fetch to fail")
+		}
+		second, err := fetchOncePerLoad(session, "key", fetch)
+		third, _ := fetchOncePerLoad(session, "key", fetch)
+		if err != nil || second != 2 || third != 2 || calls != 2 {
+			t.Errorf("Got %v, %v, %v after %d calls", second, third, err, calls)
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package goconfig
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// NewPropertiesKeyStore reads a Java .properties file and returns a KeyStore for its values.
+// By default names are mapped with EnvStyleKeys, so db.max.conns satisfies key:"DB_MAX_CONNS", as for the other
+// file stores. Use WithKeyMapping(nil) to keep the names as they are, which suits WithKeyNaming(DotCaseKeys).
+//
+// The grammar is that of java.util.Properties: keys are separated from values by =, : or whitespace,
+// lines starting with # or ! are comments, a trailing \ continues the line and \uXXXX escapes are supported.
+// The file is read as UTF-8. If a key is repeated then the last value wins.
+func NewPropertiesKeyStore(filename string, options ...FileStoreOption) (KeyStore, error) {
+	return loadFileKeyStore(filename, parseProperties, EnvStyleKeys, options)
+}
+
+// logicalLine is a properties line after joining continuations. Each character records its position in the file
+// for error reporting.
+type logicalLine struct {
+	chars   []rune
+	lines   []int
+	columns []int
+}
+
+func (l *logicalLine) add(r rune, line, column int) {
+	l.chars = append(l.chars, r)
+	l.lines = append(l.lines, line)
+	l.columns = append(l.columns, column)
+}
+
+// parseProperties parses .properties content.
+func parseProperties(filename string, content string) ([]fileEntry, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	physical := strings.Split(strings.ReplaceAll(content, "\r", "\n"), "\n")
+
+	var entries []fileEntry
+	for i := 0; i < len(physical); i++ {
+		first := []rune(physical[i])
+		start := skipPropertiesSpace(first, 0)
+		if start == len(first) || first[start] == '#' || first[start] == '!' {
+			continue
+		}
+
+		// Join continuation lines. Leading whitespace on continuation lines is discarded
+		logical := &logicalLine{}
+		runes, offset, lineNumber := first, start, i+1
+		for {
+			continued := endsWithContinuation(runes)
+			end := len(runes)
+			if continued {
+				end--
+			}
+			for j := offset; j < end; j++ {
+				logical.add(runes[j], lineNumber, j+1)
+			}
+			if !continued || i+1 >= len(physical) {
+				break
+			}
+			i++
+			runes, lineNumber = []rune(physical[i]), i+1
+			offset = skipPropertiesSpace(runes, 0)
+		}
+
+		entry, err := parsePropertiesLine(filename, logical)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parsePropertiesLine splits a logical line into key and value.
+func parsePropertiesLine(filename string, line *logicalLine) (fileEntry, error) {
+	chars := line.chars
+	keyEnd := 0
+	for keyEnd < len(chars) {
+		c := chars[keyEnd]
+		if c == '\\' {
+			keyEnd += 2
+			continue
+		}
+		if c == '=' || c == ':' || isPropertiesSpace(c) {
+			break
+		}
+		keyEnd++
+	}
+	keyEnd = min(keyEnd, len(chars))
+
+	valueStart := skipPropertiesSpace(chars, keyEnd)
+	if valueStart < len(chars) && (chars[valueStart] == '=' || chars[valueStart] == ':') {
+		valueStart = skipPropertiesSpace(chars, valueStart+1)
+	}
+
+	key, err := unescapeProperties(filename, line, 0, keyEnd)
+	if err != nil {
+		return fileEntry{}, err
+	}
+	value, err := unescapeProperties(filename, line, valueStart, len(chars))
+	if err != nil {
+		return fileEntry{}, err
+	}
+	return fileEntry{Key: key, Value: value, Line: line.lines[0]}, nil
+}
+
+// unescapeProperties processes the escapes in chars[start:end].
+func unescapeProperties(filename string, line *logicalLine, start, end int) (string, error) {
+	var result strings.Builder
+	chars := line.chars
+	for i := start; i < end; i++ {
+		c := chars[i]
+		if c != '\\' || i+1 >= end {
+			result.WriteRune(c)
+			continue
+		}
+		i++
+		switch chars[i] {
+		case 't':
+			result.WriteRune('\t')
+		case 'n':
+			result.WriteRune('\n')
+		case 'r':
+			result.WriteRune('\r')
+		case 'f':
+			result.WriteRune('\f')
+		case 'u':
+			code, ok := parseUnicodeEscape(chars, i, end)
+			if !ok {
+				return "", &SyntaxError{File: filename, Line: line.lines[i-1], Column: line.columns[i-1], Msg: "malformed \\uXXXX escape"}
+			}
+			i += 4
+			// Characters outside the Basic Multilingual Plane are written as a UTF-16 surrogate pair
+			if utf16.IsSurrogate(code) && i+2 < end && chars[i+1] == '\\' && chars[i+2] == 'u' {
+				if low, ok := parseUnicodeEscape(chars, i+2, end); ok {
+					if decoded := utf16.DecodeRune(code, low); decoded != unicode.ReplacementChar {
+						code = decoded
+						i += 6
+					}
+				}
+			}
+			result.WriteRune(code)
+		default:
+			result.WriteRune(chars[i])
+		}
+	}
+	return result.String(), nil
+}
+
+// parseUnicodeEscape reads the four hex digits following the u at chars[i].
+func parseUnicodeEscape(chars []rune, i, end int) (rune, bool) {
+	if i+5 > end {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(string(chars[i+1:i+5]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(code), true
+}
+
+// endsWithContinuation reports whether the line ends in an odd number of backslashes.
+func endsWithContinuation(runes []rune) bool {
+	count := 0
+	for i := len(runes) - 1; i >= 0 && runes[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+func skipPropertiesSpace(runes []rune, i int) int {
+	for i < len(runes) && isPropertiesSpace(runes[i]) {
+		i++
+	}
+	return i
+}
+
+func isPropertiesSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\f'
+}
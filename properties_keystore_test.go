@@ -0,0 +1,143 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []fileEntry
+		wantErr *SyntaxError
+	}{
+		{
+			name:  "separators",
+			input: "a=1\nb:2\nc 3\nd = 4\ne\t:\t5\nf  =  = 6\ng\n",
+			want: []fileEntry{
+				{"a", "1", 1}, {"b", "2", 2}, {"c", "3", 3}, {"d", "4", 4},
+				{"e", "5", 5}, {"f", "= 6", 6}, {"g", "", 7},
+			},
+		},
+		{
+			name:  "comments and blank lines",
+			input: "# comment\n  ! also a comment\n\n   \nkey=value # not a comment",
+			want:  []fileEntry{{"key", "value # not a comment", 5}},
+		},
+		{
+			name:  "continuation lines",
+			input: "fruits = apple, \\\n         banana, \\\n  # not a comment \\\n    pear\nnext=1",
+			want: []fileEntry{
+				{"fruits", "apple, banana, # not a comment pear", 1},
+				{"next", "1", 5},
+			},
+		},
+		{
+			name:  "escaped backslash is not a continuation",
+			input: "path=c:\\\\dir\\\\\nnext=1",
+			want:  []fileEntry{{"path", `c:\dir\`, 1}, {"next", "1", 2}},
+		},
+		{
+			name:  "escapes",
+			input: `tab=a\tb` + "\n" + `unicode=caf\u00e9 \uD83D\uDE00` + "\n" + `other=\q\=\:`,
+			want: []fileEntry{
+				{"tab", "a\tb", 1},
+				{"unicode", "café 😀", 2},
+				{"other", "q=:", 3},
+			},
+		},
+		{
+			name:  "escaped separators in keys",
+			input: `key\ with\=odd\:chars = value`,
+			want:  []fileEntry{{"key with=odd:chars", "value", 1}},
+		},
+		{
+			name:  "line endings",
+			input: "a=1\r\nb=2\rc=3",
+			want:  []fileEntry{{"a", "1", 1}, {"b", "2", 2}, {"c", "3", 3}},
+		},
+		{
+			name:  "continuation at end of file",
+			input: "a=1\\",
+			want:  []fileEntry{{"a", "1", 1}},
+		},
+		{
+			name:    "malformed unicode escape",
+			input:   "a=1\nb=x\\u12G4",
+			wantErr: &SyntaxError{File: "test.properties", Line: 2, Column: 4, Msg: "malformed \\uXXXX escape"},
+		},
+		{
+			name:    "truncated unicode escape on continuation line",
+			input:   "a=1 \\\n  \\u12",
+			wantErr: &SyntaxError{File: "test.properties", Line: 2, Column: 3, Msg: "malformed \\uXXXX escape"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProperties("test.properties", tt.input)
+			if tt.wantErr != nil {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) || *syntaxErr != *tt.wantErr {
+					t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPropertiesKeyStore(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "app.properties")
+	content := "db.host=localhost\ndb.maxConns=10\nserver.port=8080\nserver.port=9090\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Names used as keys without mapping", func(t *testing.T) {
+		store, err := NewPropertiesKeyStore(filename, WithKeyMapping(nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if val, ok, _ := store(ctx, "server.port"); !ok || val != "9090" {
+			t.Errorf("Got (%q, %v), want last value", val, ok)
+		}
+	})
+
+	t.Run("Env style mapping by default with Load", func(t *testing.T) {
+		store, err := NewPropertiesKeyStore(filename)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		type Config struct {
+			Host     string `key:"DB_HOST"`
+			MaxConns int    `key:"DB_MAX_CONNS"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(store)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Host != "localhost" || cfg.MaxConns != 10 {
+			t.Errorf("Got %+v", cfg)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := NewPropertiesKeyStore(filepath.Join(t.TempDir(), "missing.properties"))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected not exist error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,305 @@
+package goconfig
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RedisOption configures NewRedisKeyStore.
+type RedisOption func(*redisSettings)
+
+type redisSettings struct {
+	username  string
+	password  string
+	database  int
+	hash      string
+	keyPrefix string
+	tls       *tls.Config
+	maxSize   int64
+}
+
+// WithRedisAuth sends AUTH when connecting. Leave the username empty for servers without ACL users.
+func WithRedisAuth(username, password string) RedisOption {
+	return func(s *redisSettings) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// WithRedisDatabase sends SELECT to use a database other than 0.
+func WithRedisDatabase(database int) RedisOption {
+	return func(s *redisSettings) { s.database = database }
+}
+
+// WithRedisHash reads keys as the fields of a hash, fetched with HGETALL, rather than as string keys.
+func WithRedisHash(name string) RedisOption {
+	return func(s *redisSettings) { s.hash = name }
+}
+
+// WithRedisKeyPrefix is prepended to each key to give the Redis key, as in "myapp:" to read PORT from myapp:PORT.
+// It does not apply to hash fields.
+func WithRedisKeyPrefix(prefix string) RedisOption {
+	return func(s *redisSettings) { s.keyPrefix = prefix }
+}
+
+// WithRedisTLS connects using TLS with the given configuration.
+func WithRedisTLS(config *tls.Config) RedisOption {
+	return func(s *redisSettings) { s.tls = config }
+}
+
+// WithRedisMaxReplySize limits the size of the server's replies, in total, for each fetch. The default is 1 MiB.
+func WithRedisMaxReplySize(bytes int64) RedisOption {
+	return func(s *redisSettings) { s.maxSize = bytes }
+}
+
+type redisCacheKey struct {
+	settings *redisSettings
+}
+
+// NewRedisKeyStore returns a KeyStore that reads keys from the Redis server at address, such as localhost:6379.
+//
+// During Load every requested key is fetched with a single MGET, or the hash with HGETALL if WithRedisHash is used.
+// The connection commands are sent in the same write, so the Load costs one round trip. Each fetch opens a new
+// connection and the context's deadline and cancellation apply to it. Keys that do not exist are not present.
+// Replies larger than 1 MiB, or the limit set by WithRedisMaxReplySize, are rejected.
+//
+//	store := goconfig.NewRedisKeyStore("redis:6379",
+//	    goconfig.WithRedisAuth("", password),
+//	    goconfig.WithRedisHash("myapp:tunables"))
+func NewRedisKeyStore(address string, options ...RedisOption) KeyStore {
+	settings := &redisSettings{maxSize: 1024 * 1024}
+	for _, opt := range options {
+		opt(settings)
+	}
+
+	return func(ctx context.Context, key string) (string, bool, error) {
+		var values any
+		var err error
+		switch {
+		case settings.hash != "":
+			values, err = fetchOncePerLoad(ctx, redisCacheKey{settings: settings}, func() (any, error) {
+				return settings.fetch(ctx, address, nil)
+			})
+		case slices.Contains(RequestedKeys(ctx), key):
+			values, err = fetchOncePerLoad(ctx, redisCacheKey{settings: settings}, func() (any, error) {
+				return settings.fetch(ctx, address, RequestedKeys(ctx))
+			})
+		default:
+			// Outside Load, or a key only needed for interpolation
+			values, err = settings.fetch(ctx, address, []string{key})
+		}
+		if err != nil {
+			return "", false, ConfigError{Key: key, Err: err}
+		}
+		value, ok := values.(map[string]string)[key]
+		return value, ok, nil
+	}
+}
+
+// fetch connects to Redis and reads the keys, or the whole hash.
+func (s *redisSettings) fetch(ctx context.Context, address string, keys []string) (map[string]string, error) {
+	values, err := s.exchange(ctx, address, keys)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("reading redis: %w", ctx.Err())
+		}
+		if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+			// The connection deadline can expire just before the context notices
+			return nil, fmt.Errorf("reading redis: %w", context.DeadlineExceeded)
+		}
+		return nil, fmt.Errorf("reading redis: %w", err)
+	}
+	return values, nil
+}
+
+func (s *redisSettings) exchange(ctx context.Context, address string, keys []string) (map[string]string, error) {
+	var conn net.Conn
+	var err error
+	if s.tls != nil {
+		dialer := &tls.Dialer{Config: s.tls}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	// Send every command at once, then read the replies in order
+	var commands [][]string
+	if s.password != "" {
+		if s.username != "" {
+			commands = append(commands, []string{"AUTH", s.username, s.password})
+		} else {
+			commands = append(commands, []string{"AUTH", s.password})
+		}
+	}
+	if s.database != 0 {
+		commands = append(commands, []string{"SELECT", strconv.Itoa(s.database)})
+	}
+	if s.hash != "" {
+		commands = append(commands, []string{"HGETALL", s.hash})
+	} else if len(keys) > 0 {
+		command := []string{"MGET"}
+		for _, key := range keys {
+			command = append(command, s.keyPrefix+key)
+		}
+		commands = append(commands, command)
+	} else {
+		return map[string]string{}, nil
+	}
+
+	writer := bufio.NewWriter(conn)
+	for _, command := range commands {
+		writeRESPCommand(writer, command)
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	limited := &io.LimitedReader{R: conn, N: s.maxSize + 1}
+	reader := bufio.NewReader(limited)
+	var reply any
+	for _, command := range commands {
+		if reply, err = readRESPReply(reader, s.maxSize); err != nil {
+			if limited.N <= 0 {
+				return nil, fmt.Errorf("reply is larger than %d bytes", s.maxSize)
+			}
+			return nil, err
+		}
+		if replyErr, ok := reply.(respError); ok {
+			return nil, fmt.Errorf("%s: %w", command[0], replyErr)
+		}
+	}
+
+	items, ok := reply.([]any)
+	if !ok {
+		return nil, errors.New("unexpected reply from redis")
+	}
+	values := make(map[string]string)
+	if s.hash != "" {
+		for i := 0; i+1 < len(items); i += 2 {
+			field, fieldOK := items[i].(string)
+			value, valueOK := items[i+1].(string)
+			if fieldOK && valueOK {
+				values[field] = value
+			}
+		}
+		return values, nil
+	}
+	for i, item := range items {
+		if value, ok := item.(string); ok && i < len(keys) {
+			values[keys[i]] = value
+		}
+	}
+	return values, nil
+}
+
+// respError is an error reply from the server.
+type respError string
+
+func (e respError) Error() string {
+	return string(e)
+}
+
+// writeRESPCommand writes a command as an array of bulk strings.
+func writeRESPCommand(w *bufio.Writer, args []string) {
+	_, _ = fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		_, _ = fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+}
+
+// readRESPReply reads a RESP2 reply. Bulk and simple strings are returned as string, null as nil, integers as
+// int64, arrays as []any and error replies as respError. Bulk strings and arrays longer than maxSize are rejected
+// before anything is allocated for them.
+func readRESPReply(r *bufio.Reader, maxSize int64) (any, error) {
+	line, err := readRESPLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, errors.New("malformed reply from redis")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return respError(line[1:]), nil
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, errors.New("malformed integer reply from redis")
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 {
+			return nil, errors.New("malformed bulk string reply from redis")
+		}
+		if int64(n) > maxSize {
+			return nil, fmt.Errorf("reply is larger than %d bytes", maxSize)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		if string(data[n:]) != "\r\n" {
+			return nil, errors.New("malformed bulk string reply from redis")
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 {
+			return nil, errors.New("malformed array reply from redis")
+		}
+		if int64(n) > maxSize {
+			return nil, fmt.Errorf("reply is larger than %d bytes", maxSize)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		items := make([]any, 0, min(n, 1024))
+		for i := 0; i < n; i++ {
+			item, err := readRESPReply(r, maxSize)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return nil, errors.New("unsupported reply from redis")
+}
+
+// readRESPLine reads a line terminated by CRLF, without the terminator.
+func readRESPLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("malformed reply from redis")
+	}
+	return line[:len(line)-2], nil
+}
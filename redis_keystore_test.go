@@ -0,0 +1,297 @@
+package goconfig
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a small in-process RESP server holding strings and hashes in numbered databases.
+type fakeRedis struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	password    string
+	stall       bool
+	databases   map[int]map[string]any
+	commands    []string
+	connections int
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeRedis{
+		listener: listener,
+		databases: map[int]map[string]any{
+			0: {
+				"PORT":       "8080",
+				"HOST":       "localhost",
+				"myapp:PORT": "9090",
+				"tunables":   map[string]string{"BATCH_SIZE": "100", "RATE": "0.5"},
+			},
+			2: {"PORT": "2222"},
+		},
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go fake.serve()
+	return fake
+}
+
+func (f *fakeRedis) address() string {
+	return f.listener.Addr().String()
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.connections++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	f.mu.Lock()
+	password, stall := f.password, f.stall
+	f.mu.Unlock()
+
+	reader := bufio.NewReader(conn)
+	authenticated := password == ""
+	database := 0
+	for {
+		args, err := readFakeRedisCommand(reader)
+		if err != nil {
+			return
+		}
+		if stall {
+			continue
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		data := f.databases[database]
+		var reply string
+		switch command := strings.ToUpper(args[0]); {
+		case command == "AUTH":
+			if args[len(args)-1] == password && (len(args) == 2 || args[1] == "app") {
+				authenticated = true
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid username-password pair or user is disabled.\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case command == "SELECT":
+			database, _ = strconv.Atoi(args[1])
+			reply = "+OK\r\n"
+		case command == "MGET":
+			reply = fmt.Sprintf("*%d\r\n", len(args)-1)
+			for _, key := range args[1:] {
+				reply += fakeRedisBulk(data[key])
+			}
+		case command == "HGETALL":
+			hash, _ := data[args[1]].(map[string]string)
+			reply = fmt.Sprintf("*%d\r\n", len(hash)*2)
+			for field, value := range hash {
+				reply += fakeRedisBulk(field) + fakeRedisBulk(value)
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func fakeRedisBulk(value any) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+	}
+	return "$-1\r\n"
+}
+
+func readFakeRedisCommand(r *bufio.Reader) ([]string, error) {
+	reply, err := readRESPReply(r, 1024*1024)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]any)
+	if !ok || len(items) == 0 {
+		return nil, errors.New("expected a command")
+	}
+	args := make([]string, len(items))
+	for i, item := range items {
+		args[i], _ = item.(string)
+	}
+	return args, nil
+}
+
+func TestRedisKeyStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Reads keys", func(t *testing.T) {
+		fake := newFakeRedis(t)
+		tests := []struct {
+			name    string
+			options []RedisOption
+			key     string
+			want    string
+			present bool
+		}{
+			{"String", nil, "PORT", "8080", true},
+			{"Missing", nil, "MISSING", "", false},
+			{"Key prefix", []RedisOption{WithRedisKeyPrefix("myapp:")}, "PORT", "9090", true},
+			{"Database", []RedisOption{WithRedisDatabase(2)}, "PORT", "2222", true},
+			{"Hash", []RedisOption{WithRedisHash("tunables")}, "BATCH_SIZE", "100", true},
+			{"Hash missing field", []RedisOption{WithRedisHash("tunables")}, "PORT", "", false},
+			{"Missing hash", []RedisOption{WithRedisHash("missing")}, "PORT", "", false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				value, ok, err := NewRedisKeyStore(fake.address(), tt.options...)(ctx, tt.key)
+				if err != nil || ok != tt.present || value != tt.want {
+					t.Errorf("store(%s) = (%q, %v, %v), want (%q, %v)", tt.key, value, ok, err, tt.want, tt.present)
+				}
+			})
+		}
+	})
+
+	t.Run("Authentication", func(t *testing.T) {
+		fake := newFakeRedis(t)
+		fake.mu.Lock()
+		fake.password = "s3cret"
+		fake.mu.Unlock()
+
+		if value, ok, err := NewRedisKeyStore(fake.address(), WithRedisAuth("", "s3cret"))(ctx, "PORT"); err != nil || !ok || value != "8080" {
+			t.Errorf("Password auth: got (%q, %v, %v)", value, ok, err)
+		}
+		if _, ok, err := NewRedisKeyStore(fake.address(), WithRedisAuth("app", "s3cret"))(ctx, "PORT"); err != nil || !ok {
+			t.Errorf("ACL auth: got (%v, %v)", ok, err)
+		}
+
+		_, _, err := NewRedisKeyStore(fake.address(), WithRedisAuth("", "wrong"))(ctx, "PORT")
+		var configErr ConfigError
+		if !errors.As(err, &configErr) || configErr.Key != "PORT" || !strings.Contains(err.Error(), "AUTH: WRONGPASS") {
+			t.Errorf("Expected AUTH error for PORT, got %v", err)
+		}
+		if strings.Contains(err.Error(), "wrong") {
+			t.Errorf("Error must not contain the password: %v", err)
+		}
+	})
+
+	t.Run("One round trip per Load", func(t *testing.T) {
+		fake := newFakeRedis(t)
+		type Config struct {
+			Port int    `key:"PORT"`
+			Host string `key:"HOST" default:"0.0.0.0"`
+			Name string `key:"NAME" default:"app"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(NewRedisKeyStore(fake.address(), WithRedisDatabase(0)))); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Port != 8080 || cfg.Host != "localhost" || cfg.Name != "app" {
+			t.Errorf("Got %+v", cfg)
+		}
+		if fake.connections != 1 || len(fake.commands) != 1 || fake.commands[0] != "MGET PORT HOST NAME" {
+			t.Errorf("Expected a single MGET on one connection, got %d connections and %v", fake.connections, fake.commands)
+		}
+	})
+
+	t.Run("Limits the reply size", func(t *testing.T) {
+		fake := newFakeRedis(t)
+		store := NewRedisKeyStore(fake.address(), WithRedisHash("tunables"), WithRedisMaxReplySize(32))
+		_, _, err := store(ctx, "BATCH_SIZE")
+		if err == nil || !strings.Contains(err.Error(), "larger than 32 bytes") {
+			t.Fatalf("Expected size error, got %v", err)
+		}
+		if strings.Contains(err.Error(), "100") {
+			t.Errorf("Error must not contain values: %v", err)
+		}
+
+		store = NewRedisKeyStore(fake.address(), WithRedisHash("tunables"))
+		if value, ok, err := store(ctx, "BATCH_SIZE"); err != nil || !ok || value != "100" {
+			t.Errorf("store() = (%q, %v, %v) with the default limit", value, ok, err)
+		}
+	})
+
+	t.Run("Honours the context deadline", func(t *testing.T) {
+		fake := newFakeRedis(t)
+		fake.mu.Lock()
+		fake.stall = true
+		fake.mu.Unlock()
+		timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		_, _, err := NewRedisKeyStore(fake.address())(timeout, "PORT")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("Honours cancellation", func(t *testing.T) {
+		fake := newFakeRedis(t)
+		fake.mu.Lock()
+		fake.stall = true
+		fake.mu.Unlock()
+		cancellable, cancel := context.WithCancel(ctx)
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, _, err := NewRedisKeyStore(fake.address())(cancellable, "PORT")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestReadRESPReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"Simple string", "+OK\r\n", "OK", false},
+		{"Error", "-ERR bad\r\n", "ERR bad", false},
+		{"Integer", ":42\r\n", "42", false},
+		{"Bulk string", "$5\r\nhello\r\n", "hello", false},
+		{"Bulk string with CRLF", "$7\r\nhel\r\nlo\r\n", "hel\r\nlo", false},
+		{"Null bulk string", "$-1\r\n", "<nil>", false},
+		{"Array", "*2\r\n$1\r\na\r\n$-1\r\n", "[a <nil>]", false},
+		{"Missing CR", "+OK\n", "", true},
+		{"Short bulk string", "$5\r\nhi\r\n", "", true},
+		{"Bad length", "$x\r\n", "", true},
+		{"Unknown type", "~1\r\n", "", true},
+		{"Bulk string over the limit", "$65\r\n", "", true},
+		{"Array over the limit", "*65\r\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, err := readRESPReply(bufio.NewReader(strings.NewReader(tt.input)), 64)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readRESPReply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := fmt.Sprint(reply); !tt.wantErr && got != tt.want {
+				t.Errorf("readRESPReply() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package goconfig
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	mathrand "math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RetryOption configures NewRetryKeyStore.
+type RetryOption func(*retrySettings)
+
+type retrySettings struct {
+	attempts     int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	retryable    func(error) bool
+	cacheFile    string
+	cacheKey     []byte
+	onStale      func(key string, confirmedAt time.Time, err error)
+}
+
+// WithRetryAttempts sets the number of times the store is tried for each lookup. The default is 3.
+func WithRetryAttempts(attempts int) RetryOption {
+	return func(s *retrySettings) { s.attempts = max(1, attempts) }
+}
+
+// WithRetryBackoff sets the delay before the first retry, which doubles for each further retry up to maxDelay.
+// The defaults are 100ms and 5s. A random jitter of up to half the delay is subtracted so that clients restarted
+// together do not retry together.
+func WithRetryBackoff(initial, maxDelay time.Duration) RetryOption {
+	return func(s *retrySettings) {
+		s.initialDelay = initial
+		s.maxDelay = maxDelay
+	}
+}
+
+// WithRetryableErrors sets which errors are worth retrying. The default is IsTransientError.
+func WithRetryableErrors(retryable func(error) bool) RetryOption {
+	return func(s *retrySettings) { s.retryable = retryable }
+}
+
+// WithLastKnownGood saves the values fetched from the store to a file encrypted with AES-GCM, and serves them when
+// the store fails. The key must be 16, 24 or 32 bytes long and should itself come from a secure source such as
+// NewKeyringKeyStore. A file that cannot be read or decrypted, for example after the key changes, is ignored and
+// replaced.
+func WithLastKnownGood(filename string, key []byte) RetryOption {
+	return func(s *retrySettings) {
+		s.cacheFile = filename
+		s.cacheKey = key
+	}
+}
+
+// WithStaleValueHandler is called when a last known good value is served in place of a failed lookup, with the time
+// the store last returned the value and the error from the store. Use it to log or report that the configuration is
+// stale.
+func WithStaleValueHandler(handler func(key string, confirmedAt time.Time, err error)) RetryOption {
+	return func(s *retrySettings) { s.onStale = handler }
+}
+
+// NewRetryKeyStore wraps a store so that transient errors are retried with exponential backoff and jitter. Retries
+// stop when the attempts are used up or the context is done, including when the context's deadline would pass
+// before the next attempt. The last error is then returned, unless WithLastKnownGood supplies a saved value. Once a
+// lookup has failed during a Load, later keys in that Load with a saved value are served it without asking the store
+// again, so an unavailable store costs one set of attempts rather than one per key.
+//
+//	store, err := goconfig.NewRetryKeyStore(vault.Lookup,
+//	    goconfig.WithRetryAttempts(5),
+//	    goconfig.WithLastKnownGood("/var/cache/myapp/config.enc", cacheKey),
+//	    goconfig.WithStaleValueHandler(func(key string, confirmedAt time.Time, err error) {
+//	        logger.Warn("using last known good value", "key", key, "confirmed_at", confirmedAt, "error", err)
+//	    }))
+func NewRetryKeyStore(store KeyStore, options ...RetryOption) (KeyStore, error) {
+	settings := &retrySettings{
+		attempts:     3,
+		initialDelay: 100 * time.Millisecond,
+		maxDelay:     5 * time.Second,
+		retryable:    IsTransientError,
+	}
+	for _, opt := range options {
+		opt(settings)
+	}
+
+	var cache *lastKnownGood
+	if settings.cacheFile != "" {
+		block, err := aes.NewCipher(settings.cacheKey)
+		if err != nil {
+			return nil, fmt.Errorf("last known good cache: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("last known good cache: %w", err)
+		}
+		cache = &lastKnownGood{filename: settings.cacheFile, aead: aead}
+	}
+
+	return func(ctx context.Context, key string) (string, bool, error) {
+		if cache == nil {
+			return settings.lookupWithRetry(ctx, store, key)
+		}
+
+		// Once the store has failed during this Load, keys with a saved value do not wait for it again
+		failure := loadFailure(ctx, cache)
+		if err := failure.get(); err != nil {
+			if value, ok := settings.serveStale(cache, key, err); ok {
+				return value, true, nil
+			}
+		}
+
+		value, present, err := settings.lookupWithRetry(ctx, store, key)
+		if err == nil {
+			cache.record(key, value, present)
+			return value, present, nil
+		}
+		failure.set(err)
+		if value, ok := settings.serveStale(cache, key, err); ok {
+			return value, true, nil
+		}
+		return "", false, err
+	}, nil
+}
+
+// serveStale returns the saved value for the key, if there is one, and reports it to the stale value handler.
+func (s *retrySettings) serveStale(cache *lastKnownGood, key string, err error) (string, bool) {
+	entry, ok := cache.get(key)
+	if !ok {
+		return "", false
+	}
+	if s.onStale != nil {
+		s.onStale(key, entry.confirmedAt(), err)
+	}
+	return entry.Value, true
+}
+
+// retryLoadKey identifies the failure remembered for a retry store during a Load.
+type retryLoadKey struct {
+	cache *lastKnownGood
+}
+
+// retryFailure remembers the first error from the store during a Load.
+type retryFailure struct {
+	mu  sync.Mutex
+	err error
+}
+
+// loadFailure returns the failure remembered for the store during the current Load. Outside of Load nothing is
+// remembered between lookups.
+func loadFailure(ctx context.Context, cache *lastKnownGood) *retryFailure {
+	failure, _ := fetchOncePerLoad(ctx, retryLoadKey{cache: cache}, func() (any, error) {
+		return &retryFailure{}, nil
+	})
+	return failure.(*retryFailure)
+}
+
+func (f *retryFailure) get() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+func (f *retryFailure) set(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+// lookupWithRetry calls the store until it succeeds, the error is not retryable, the attempts are used up or the
+// context is done.
+func (s *retrySettings) lookupWithRetry(ctx context.Context, store KeyStore, key string) (string, bool, error) {
+	delay := s.initialDelay
+	for attempt := 1; ; attempt++ {
+		value, present, err := store(ctx, key)
+		if err == nil || attempt >= s.attempts || !s.retryable(err) || ctx.Err() != nil {
+			return value, present, err
+		}
+
+		wait := delay - mathrand.N(delay/2+1)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return value, present, err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return value, present, err
+		case <-timer.C:
+		}
+		delay = min(delay*2, s.maxDelay)
+	}
+}
+
+// IsTransientError reports whether a KeyStore error might succeed if retried. Syntax errors, context errors,
+// interpolation cycles, ErrKeyringUnsupported, revoked or expired keyring keys, missing files, permission errors such
+// as EACCES, rejected secret files and HTTP client errors other than 408 Request Timeout and 429 Too Many Requests are
+// not transient. This covers permission errors from Vault and Consul, which are returned as an *HTTPStatusError.
+// Redis error replies are only transient if the server is loading, busy or asks for a retry.
+func IsTransientError(err error) bool {
+	var syntaxErr *SyntaxError
+	var fileErr secretFileError
+	var statusErr *HTTPStatusError
+	var replyErr respError
+	switch {
+	case err == nil, isContextError(err), errors.Is(err, ErrKeyringUnsupported), errors.Is(err, ErrInterpolationCycle),
+		errors.Is(err, fs.ErrPermission), errors.Is(err, fs.ErrNotExist), isAbsentKeyError(err),
+		errors.As(err, &syntaxErr), errors.As(err, &fileErr):
+		return false
+	case errors.As(err, &statusErr):
+		return statusErr.StatusCode >= 500 ||
+			statusErr.StatusCode == http.StatusRequestTimeout || statusErr.StatusCode == http.StatusTooManyRequests
+	case errors.As(err, &replyErr):
+		code, _, _ := strings.Cut(string(replyErr), " ")
+		return slices.Contains([]string{"LOADING", "BUSY", "TRYAGAIN", "MASTERDOWN", "CLUSTERDOWN"}, code)
+	}
+	return true
+}
+
+// lastKnownGood is the encrypted file of values saved by NewRetryKeyStore.
+type lastKnownGood struct {
+	filename string
+	aead     cipher.AEAD
+
+	mu      sync.Mutex
+	entries map[string]lastKnownGoodEntry
+	// savedAt is when the file was last written, which limits how often confirmations are saved
+	savedAt time.Time
+}
+
+type lastKnownGoodEntry struct {
+	Value    string    `json:"value"`
+	StoredAt time.Time `json:"storedAt"`
+	// ConfirmedAt is when the store last returned the value. It is kept in memory on every lookup, but only saved
+	// with other changes or once per lastKnownGoodSaveInterval.
+	ConfirmedAt time.Time `json:"confirmedAt"`
+}
+
+// lastKnownGoodSaveInterval is how often the file is rewritten only to save confirmation times.
+const lastKnownGoodSaveInterval = time.Minute
+
+// confirmedAt returns when the store last returned the value. Files written by older versions only have StoredAt.
+func (e lastKnownGoodEntry) confirmedAt() time.Time {
+	if e.ConfirmedAt.IsZero() {
+		return e.StoredAt
+	}
+	return e.ConfirmedAt
+}
+
+// lastKnownGoodData is authenticated with the file's content so that other encrypted data is not accepted.
+var lastKnownGoodData = []byte("goconfig last known good v1")
+
+// get returns the saved value for the key.
+func (c *lastKnownGood) get(key string) (lastKnownGoodEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+// record saves the result of a successful lookup. Keys that are no longer present are removed. Unchanged values
+// only update the confirmation time, and the file is rewritten for that at most once per lastKnownGoodSaveInterval,
+// so repeated lookups cost little.
+func (c *lastKnownGood) record(key, value string, present bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	now := time.Now()
+	entry, exists := c.entries[key]
+	switch {
+	case !present && !exists:
+		return
+	case present && exists && entry.Value == value:
+		entry.ConfirmedAt = now
+		c.entries[key] = entry
+		if now.Sub(c.savedAt) < lastKnownGoodSaveInterval {
+			return
+		}
+	case present:
+		c.entries[key] = lastKnownGoodEntry{Value: value, StoredAt: now, ConfirmedAt: now}
+	default:
+		delete(c.entries, key)
+	}
+	// The cache is a fallback, so failing to save it must not fail the lookup
+	if c.save() == nil {
+		c.savedAt = now
+	}
+}
+
+// load reads the file the first time it is needed.
+func (c *lastKnownGood) load() {
+	if c.entries != nil {
+		return
+	}
+	c.entries = make(map[string]lastKnownGoodEntry)
+
+	if info, err := os.Stat(c.filename); err == nil {
+		c.savedAt = info.ModTime()
+	}
+	content, err := os.ReadFile(c.filename)
+	nonceSize := c.aead.NonceSize()
+	if err != nil || len(content) < nonceSize {
+		return
+	}
+	plaintext, err := c.aead.Open(nil, content[:nonceSize], content[nonceSize:], lastKnownGoodData)
+	if err != nil {
+		return
+	}
+	var entries map[string]lastKnownGoodEntry
+	if json.Unmarshal(plaintext, &entries) == nil && entries != nil {
+		c.entries = entries
+	}
+}
+
+// save encrypts the entries and replaces the file atomically.
+func (c *lastKnownGood) save() error {
+	plaintext, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	content := c.aead.Seal(nonce, nonce, plaintext, lastKnownGoodData)
+
+	temp, err := os.CreateTemp(filepath.Dir(c.filename), "."+filepath.Base(c.filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(content); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), c.filename)
+}
@@ -0,0 +1,364 @@
+package goconfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNewRetryKeyStore(t *testing.T) {
+	ctx := context.Background()
+	errUnavailable := errors.New("backend unavailable")
+
+	// flakyStore fails the given number of times before returning PORT.
+	flakyStore := func(failures int, err error) (KeyStore, *int) {
+		calls := 0
+		return func(ctx context.Context, key string) (string, bool, error) {
+			calls++
+			if calls <= failures {
+				return "", false, err
+			}
+			return "8080", true, nil
+		}, &calls
+	}
+	fastRetry := WithRetryBackoff(time.Millisecond, 2*time.Millisecond)
+
+	t.Run("Retries", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			failures  int
+			err       error
+			wantCalls int
+			wantErr   bool
+		}{
+			{"Succeeds after retries", 2, errUnavailable, 3, false},
+			{"Attempts used up", 5, errUnavailable, 3, true},
+			{"Syntax errors are not retried", 5, &SyntaxError{File: "app.env", Line: 1, Msg: "bad"}, 1, true},
+			{"Client errors are not retried", 5, &HTTPStatusError{StatusCode: http.StatusNotFound}, 1, true},
+			{"Server errors are retried", 2, ConfigError{Key: "PORT", Err: &HTTPStatusError{StatusCode: http.StatusBadGateway}}, 3, false},
+			{"Too many requests is retried", 1, &HTTPStatusError{StatusCode: http.StatusTooManyRequests}, 2, false},
+			{"Vault permission errors are not retried", 5, fmt.Errorf("%w: %w", &HTTPStatusError{StatusCode: http.StatusForbidden}, errVaultForbidden), 1, true},
+			{"Redis authentication errors are not retried", 5, fmt.Errorf("AUTH: %w", respError("WRONGPASS invalid password")), 1, true},
+			{"Redis loading is retried", 1, fmt.Errorf("MGET: %w", respError("LOADING Redis is loading the dataset")), 2, false},
+			{"Unsupported keyring is not retried", 5, ErrKeyringUnsupported, 1, true},
+			{"Permission errors are not retried", 5, fs.ErrPermission, 1, true},
+			{"EACCES is not retried", 5, &fs.PathError{Op: "open", Path: "/run/secrets/db", Err: syscall.EACCES}, 1, true},
+			{"Missing files are not retried", 5, &fs.PathError{Op: "open", Path: "/run/secrets/db", Err: fs.ErrNotExist}, 1, true},
+			{"Interpolation cycles are not retried", 5, fmt.Errorf("%w: A -> B -> A", ErrInterpolationCycle), 1, true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				inner, calls := flakyStore(tt.failures, tt.err)
+				store, err := NewRetryKeyStore(inner, fastRetry)
+				if err != nil {
+					t.Fatal(err)
+				}
+				value, _, err := store(ctx, "PORT")
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("store() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && value != "8080" {
+					t.Errorf("store() = %q, want 8080", value)
+				}
+				if *calls != tt.wantCalls {
+					t.Errorf("Expected %d calls, got %d", tt.wantCalls, *calls)
+				}
+			})
+		}
+	})
+
+	t.Run("Rejected secret files are not retried", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "db_password")
+		if err := os.WriteFile(filename, []byte("s3cret"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(filename, 0640); err != nil {
+			t.Fatal(err)
+		}
+		for _, option := range []FileSecretOption{WithMaxFileSize(4), WithForbiddenPermissions(0o077)} {
+			calls := 0
+			env := func(ctx context.Context, key string) (string, bool, error) {
+				calls++
+				if key == "DB_PASSWORD_FILE" {
+					return filename, true, nil
+				}
+				return "", false, nil
+			}
+			store, err := NewRetryKeyStore(NewFileSecretKeyStore(env, option), fastRetry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := store(ctx, "DB_PASSWORD"); err == nil {
+				t.Fatal("Expected the file to be rejected")
+			}
+			if calls != 2 {
+				t.Errorf("Expected a single attempt of two lookups, got %d lookups", calls)
+			}
+		}
+	})
+
+	t.Run("Retries are bounded by the context", func(t *testing.T) {
+		inner, calls := flakyStore(100, errUnavailable)
+		store, err := NewRetryKeyStore(inner, WithRetryAttempts(100), WithRetryBackoff(20*time.Millisecond, time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if _, _, err := store(timeout, "PORT"); !errors.Is(err, errUnavailable) {
+			t.Errorf("Expected the store's error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected to stop at the deadline, took %v", elapsed)
+		}
+		if *calls < 2 || *calls > 5 {
+			t.Errorf("Expected a few attempts before the deadline, got %d", *calls)
+		}
+	})
+
+	t.Run("Retries within Load", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("PORT=8080\n"))
+		}))
+		defer server.Close()
+
+		store, err := NewRetryKeyStore(NewHTTPDocumentKeyStore(server.URL+"/app.env", DotEnvFormat), fastRetry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cfg struct {
+			Port int `key:"PORT"`
+		}
+		if err := Load(ctx, &cfg, WithKeyStore(store)); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Port != 8080 || requests.Load() != 2 {
+			t.Errorf("Got %+v after %d requests", cfg, requests.Load())
+		}
+	})
+
+	t.Run("Invalid cache key", func(t *testing.T) {
+		if _, err := NewRetryKeyStore(EnvironmentKeyStore, WithLastKnownGood("cache", []byte("short"))); err == nil {
+			t.Error("Expected error for invalid key")
+		}
+	})
+}
+
+func TestLastKnownGood(t *testing.T) {
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{1}, 32)
+	errUnavailable := errors.New("backend unavailable")
+
+	values := map[string]string{"DB_PASSWORD": "s3cret-password", "PORT": "8080"}
+	backendDown := false
+	backend := func(ctx context.Context, k string) (string, bool, error) {
+		if backendDown {
+			return "", false, errUnavailable
+		}
+		value, ok := values[k]
+		return value, ok, nil
+	}
+
+	type staleReport struct {
+		key         string
+		confirmedAt time.Time
+		err         error
+	}
+	newStore := func(t *testing.T, filename string, cacheKey []byte) (KeyStore, *[]staleReport) {
+		t.Helper()
+		var reports []staleReport
+		store, err := NewRetryKeyStore(backend, WithRetryAttempts(1), WithLastKnownGood(filename, cacheKey),
+			WithStaleValueHandler(func(key string, confirmedAt time.Time, err error) {
+				reports = append(reports, staleReport{key, confirmedAt, err})
+			}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store, &reports
+	}
+
+	filename := filepath.Join(t.TempDir(), "config.cache")
+	before := time.Now()
+
+	// Populate the cache while the backend is up
+	store, _ := newStore(t, filename, key)
+	for _, k := range []string{"DB_PASSWORD", "PORT"} {
+		if _, ok, err := store(ctx, k); !ok || err != nil {
+			t.Fatalf("store(%s) = (%v, %v)", k, ok, err)
+		}
+	}
+
+	t.Run("File is encrypted", func(t *testing.T) {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(content, []byte("s3cret-password")) || bytes.Contains(content, []byte("DB_PASSWORD")) {
+			t.Error("Cache file contains plain text")
+		}
+		if info, err := os.Stat(filename); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+		}
+	})
+
+	t.Run("File is only written when values change", func(t *testing.T) {
+		before, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store, _ := newStore(t, filename, key)
+		_, _, _ = store(ctx, "PORT")
+		if after, _ := os.ReadFile(filename); !bytes.Equal(before, after) {
+			t.Error("Expected an unchanged value not to rewrite the file")
+		}
+
+		values["PORT"] = "9090"
+		defer func() { values["PORT"] = "8080" }()
+		_, _, _ = store(ctx, "PORT")
+		_, _, _ = store(ctx, "PORT")
+		values["PORT"] = "8080"
+		_, _, _ = store(ctx, "PORT")
+		if after, _ := os.ReadFile(filename); bytes.Equal(before, after) {
+			t.Error("Expected a changed value to rewrite the file")
+		}
+	})
+
+	t.Run("Serves stale values when the backend is down", func(t *testing.T) {
+		backendDown = true
+		defer func() { backendDown = false }()
+
+		// A new store, as after a restart, reads the file
+		store, reports := newStore(t, filename, key)
+		value, ok, err := store(ctx, "DB_PASSWORD")
+		if err != nil || !ok || value != "s3cret-password" {
+			t.Fatalf("store() = (%q, %v, %v)", value, ok, err)
+		}
+		if len(*reports) != 1 {
+			t.Fatalf("Expected a stale report, got %v", *reports)
+		}
+		report := (*reports)[0]
+		if report.key != "DB_PASSWORD" || !errors.Is(report.err, errUnavailable) || report.confirmedAt.Before(before) {
+			t.Errorf("Unexpected report %+v", report)
+		}
+
+		if _, _, err := store(ctx, "NEVER_FETCHED"); !errors.Is(err, errUnavailable) {
+			t.Errorf("Expected error for a key with no saved value, got %v", err)
+		}
+	})
+
+	t.Run("Reports when the value was last confirmed", func(t *testing.T) {
+		store, reports := newStore(t, filename, key)
+		_, _, _ = store(ctx, "PORT")
+		time.Sleep(10 * time.Millisecond)
+		confirmed := time.Now()
+		if value, _, err := store(ctx, "PORT"); err != nil || value != "8080" {
+			t.Fatalf("store() = (%q, %v)", value, err)
+		}
+
+		backendDown = true
+		defer func() { backendDown = false }()
+		if _, _, err := store(ctx, "PORT"); err != nil {
+			t.Fatalf("store() error = %v", err)
+		}
+		if len(*reports) != 1 || (*reports)[0].confirmedAt.Before(confirmed) {
+			t.Errorf("Expected the report to give the last successful lookup after %v, got %v", confirmed, *reports)
+		}
+	})
+
+	t.Run("Wrong key ignores the file", func(t *testing.T) {
+		backendDown = true
+		defer func() { backendDown = false }()
+
+		store, _ := newStore(t, filename, bytes.Repeat([]byte{2}, 32))
+		if _, _, err := store(ctx, "DB_PASSWORD"); !errors.Is(err, errUnavailable) {
+			t.Errorf("Expected the store's error, got %v", err)
+		}
+	})
+
+	t.Run("Absent keys are removed", func(t *testing.T) {
+		delete(values, "PORT")
+		defer func() { values["PORT"] = "8080" }()
+		store, _ := newStore(t, filename, key)
+		if _, ok, _ := store(ctx, "PORT"); ok {
+			t.Fatal("Expected PORT to be absent")
+		}
+
+		backendDown = true
+		defer func() { backendDown = false }()
+		store, _ = newStore(t, filename, key)
+		if _, _, err := store(ctx, "PORT"); err == nil {
+			t.Error("Expected no saved value for PORT")
+		}
+	})
+
+	t.Run("Load uses stale values", func(t *testing.T) {
+		backendDown = true
+		defer func() { backendDown = false }()
+
+		store, reports := newStore(t, filename, key)
+		type Config struct {
+			Password string `key:"DB_PASSWORD"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(store)); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Password != "s3cret-password" || len(*reports) != 1 {
+			t.Errorf("Got %+v with reports %v", cfg, *reports)
+		}
+	})
+
+	t.Run("Later keys in a Load skip a failed store", func(t *testing.T) {
+		calls := 0
+		counting := func(ctx context.Context, k string) (string, bool, error) {
+			calls++
+			return backend(ctx, k)
+		}
+		store, err := NewRetryKeyStore(counting, WithRetryAttempts(3), WithRetryBackoff(time.Millisecond, time.Millisecond),
+			WithLastKnownGood(filename, key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, err := store(ctx, "PORT"); !ok || err != nil {
+			t.Fatalf("store(PORT) = (%v, %v)", ok, err)
+		}
+
+		backendDown = true
+		defer func() { backendDown = false }()
+		calls = 0
+		type Config struct {
+			Password string `key:"DB_PASSWORD"`
+			Port     int    `key:"PORT"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(store)); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Password != "s3cret-password" || cfg.Port != 8080 || calls != 3 {
+			t.Errorf("Got %+v after %d calls, want one set of attempts", cfg, calls)
+		}
+
+		// A later Load tries the store again
+		calls = 0
+		if err := Load(ctx, &cfg, WithKeyStore(store)); err != nil || calls != 3 {
+			t.Errorf("Load() error = %v after %d calls", err, calls)
+		}
+	})
+}
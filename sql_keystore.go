@@ -0,0 +1,147 @@
+package goconfig
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SQLOption configures NewSQLKeyStore.
+type SQLOption func(*sqlSettings)
+
+type sqlSettings struct {
+	table       string
+	keyColumn   string
+	valueColumn string
+	scopeColumn string
+	scope       any
+	placeholder string
+	query       string
+	args        []any
+}
+
+// WithSQLTable sets the table name. The default is settings.
+func WithSQLTable(table string) SQLOption {
+	return func(s *sqlSettings) { s.table = table }
+}
+
+// WithSQLColumns sets the key and value column names. The defaults are name and value, which no common database
+// reserves. Names are used as given, so quote them if the database needs it, as in WithSQLColumns("`key`", "`value`")
+// for MySQL.
+func WithSQLColumns(keyColumn, valueColumn string) SQLOption {
+	return func(s *sqlSettings) {
+		s.keyColumn = keyColumn
+		s.valueColumn = valueColumn
+	}
+}
+
+// WithSQLScope only reads rows whose scope column, such as a tenant or application name, equals the value.
+func WithSQLScope(column string, value any) SQLOption {
+	return func(s *sqlSettings) {
+		s.scopeColumn = column
+		s.scope = value
+	}
+}
+
+// WithSQLPlaceholder sets the placeholder used for the scope value. The default is ? as used by MySQL and SQLite.
+// Use $1 for PostgreSQL or @p1 for SQL Server.
+func WithSQLPlaceholder(placeholder string) SQLOption {
+	return func(s *sqlSettings) { s.placeholder = placeholder }
+}
+
+// WithSQLQuery replaces the generated query. The query must return the key and value as its first two columns.
+// The table, column and scope options are ignored.
+func WithSQLQuery(query string, args ...any) SQLOption {
+	return func(s *sqlSettings) {
+		s.query = query
+		s.args = args
+	}
+}
+
+type sqlCacheKey struct {
+	settings *sqlSettings
+}
+
+// NewSQLKeyStore returns a KeyStore that reads keys from a settings table. All the settings are read in a single
+// query once per Load, using the context passed to the KeyStore. By default the query is
+//
+//	SELECT name, value FROM settings
+//
+// The defaults, including the ? placeholder, suit MySQL and SQLite. Use WithSQLPlaceholder for other databases.
+// Rows with a NULL value are not present. If a key appears more than once, the first row returned wins.
+// Table and column names are written into the query as given, so they must not come from untrusted input.
+//
+//	store := goconfig.NewSQLKeyStore(db,
+//	    goconfig.WithSQLTable("app_settings"),
+//	    goconfig.WithSQLScope("tenant", tenantID),
+//	    goconfig.WithSQLPlaceholder("$1"))
+func NewSQLKeyStore(db *sql.DB, options ...SQLOption) KeyStore {
+	settings := &sqlSettings{
+		table:       "settings",
+		keyColumn:   "name",
+		valueColumn: "value",
+		placeholder: "?",
+	}
+	for _, opt := range options {
+		opt(settings)
+	}
+
+	query, args := settings.query, settings.args
+	if query == "" {
+		query = fmt.Sprintf("SELECT %s, %s FROM %s", settings.keyColumn, settings.valueColumn, settings.table)
+		if settings.scopeColumn != "" {
+			query += fmt.Sprintf(" WHERE %s = %s", settings.scopeColumn, settings.placeholder)
+			args = []any{settings.scope}
+		}
+	}
+
+	return func(ctx context.Context, key string) (string, bool, error) {
+		values, err := fetchOncePerLoad(ctx, sqlCacheKey{settings: settings}, func() (any, error) {
+			return readSQLSettings(ctx, db, query, args)
+		})
+		if err != nil {
+			return "", false, ConfigError{Key: key, Err: err}
+		}
+		value, ok := values.(map[string]string)[key]
+		return value, ok, nil
+	}
+}
+
+// readSQLSettings runs the query and collects the key and value columns.
+func readSQLSettings(ctx context.Context, db *sql.DB, query string, args []any) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading settings: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("reading settings: %w", err)
+	}
+	if len(columns) < 2 {
+		return nil, fmt.Errorf("reading settings: query must return key and value columns")
+	}
+
+	values := make(map[string]string)
+	scanned := make([]any, len(columns))
+	var key string
+	var value sql.NullString
+	scanned[0], scanned[1] = &key, &value
+	for i := 2; i < len(columns); i++ {
+		scanned[i] = new(any)
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanned...); err != nil {
+			// Conversion errors quote the value, so are not included
+			return nil, fmt.Errorf("reading settings: key and value columns must be text")
+		}
+		if _, exists := values[key]; value.Valid && !exists {
+			values[key] = value.String
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading settings: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,197 @@
+package goconfig
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSQLDriver is a database/sql driver that answers every query with fixed rows and records the queries.
+type fakeSQLDriver struct {
+	mu      sync.Mutex
+	columns []string
+	rows    [][]driver.Value
+	queries []string
+	args    [][]driver.NamedValue
+	err     error
+}
+
+func (d *fakeSQLDriver) Open(string) (driver.Conn, error) { return &fakeSQLConn{driver: d}, nil }
+func (d *fakeSQLDriver) Connect(context.Context) (driver.Conn, error) {
+	return &fakeSQLConn{driver: d}, nil
+}
+func (d *fakeSQLDriver) Driver() driver.Driver { return d }
+
+type fakeSQLConn struct {
+	driver *fakeSQLDriver
+}
+
+func (c *fakeSQLConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *fakeSQLConn) Close() error { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+func (c *fakeSQLConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	d := c.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, query)
+	d.args = append(d.args, args)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &fakeSQLRows{columns: d.columns, rows: d.rows}, nil
+}
+
+type fakeSQLRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeSQLRows) Columns() []string { return r.columns }
+func (r *fakeSQLRows) Close() error      { return nil }
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func newFakeSQL(t *testing.T) (*fakeSQLDriver, *sql.DB) {
+	fake := &fakeSQLDriver{
+		columns: []string{"name", "value"},
+		rows: [][]driver.Value{
+			{"PORT", "8080"},
+			{"HOST", []byte("localhost")},
+			{"UNSET", nil},
+			{"PORT", "9090"},
+		},
+	}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { _ = db.Close() })
+	return fake, db
+}
+
+func TestSQLKeyStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Reads settings", func(t *testing.T) {
+		_, db := newFakeSQL(t)
+		store := NewSQLKeyStore(db)
+
+		tests := []struct {
+			key     string
+			want    string
+			present bool
+		}{
+			{"PORT", "8080", true},
+			{"HOST", "localhost", true},
+			{"UNSET", "", false},
+			{"MISSING", "", false},
+		}
+		for _, tt := range tests {
+			value, ok, err := store(ctx, tt.key)
+			if err != nil || ok != tt.present || value != tt.want {
+				t.Errorf("store(%s) = (%q, %v, %v), want (%q, %v)", tt.key, value, ok, err, tt.want, tt.present)
+			}
+		}
+	})
+
+	t.Run("Queries", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			options   []SQLOption
+			wantQuery string
+			wantArgs  []any
+		}{
+			{"Default", nil, "SELECT name, value FROM settings", nil},
+			{"Table and columns", []SQLOption{WithSQLTable("app_config"), WithSQLColumns("setting", "setting_value")},
+				"SELECT setting, setting_value FROM app_config", nil},
+			{"Scope", []SQLOption{WithSQLScope("tenant", "acme")},
+				"SELECT name, value FROM settings WHERE tenant = ?", []any{"acme"}},
+			{"Scope with placeholder", []SQLOption{WithSQLScope("tenant_id", int64(42)), WithSQLPlaceholder("$1")},
+				"SELECT name, value FROM settings WHERE tenant_id = $1", []any{int64(42)}},
+			{"Custom query", []SQLOption{WithSQLTable("ignored"), WithSQLQuery("SELECT k, v FROM kv WHERE app = ?", "web")},
+				"SELECT k, v FROM kv WHERE app = ?", []any{"web"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				fake, db := newFakeSQL(t)
+				if _, _, err := NewSQLKeyStore(db, tt.options...)(ctx, "PORT"); err != nil {
+					t.Fatal(err)
+				}
+				if fake.queries[0] != tt.wantQuery {
+					t.Errorf("Query = %q, want %q", fake.queries[0], tt.wantQuery)
+				}
+				if len(fake.args[0]) != len(tt.wantArgs) {
+					t.Fatalf("Args = %v, want %v", fake.args[0], tt.wantArgs)
+				}
+				for i, arg := range fake.args[0] {
+					if arg.Value != tt.wantArgs[i] {
+						t.Errorf("Arg %d = %v, want %v", i, arg.Value, tt.wantArgs[i])
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("One query per Load", func(t *testing.T) {
+		fake, db := newFakeSQL(t)
+		type Config struct {
+			Port int    `key:"PORT"`
+			Host string `key:"HOST"`
+			Name string `key:"NAME" default:"app"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(NewSQLKeyStore(db))); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Port != 8080 || cfg.Host != "localhost" || cfg.Name != "app" {
+			t.Errorf("Got %+v", cfg)
+		}
+		if len(fake.queries) != 1 {
+			t.Errorf("Expected 1 query, got %d", len(fake.queries))
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		fake, db := newFakeSQL(t)
+		fake.err = errors.New("no such table: settings")
+		_, _, err := NewSQLKeyStore(db)(ctx, "PORT")
+		var configErr ConfigError
+		if !errors.As(err, &configErr) || configErr.Key != "PORT" || !strings.Contains(err.Error(), "no such table") {
+			t.Errorf("Expected ConfigError for PORT, got %v", err)
+		}
+	})
+
+	t.Run("Too few columns", func(t *testing.T) {
+		fake, db := newFakeSQL(t)
+		fake.columns = []string{"name"}
+		fake.rows = nil
+		if _, _, err := NewSQLKeyStore(db)(ctx, "PORT"); err == nil || !strings.Contains(err.Error(), "key and value columns") {
+			t.Errorf("Expected column error, got %v", err)
+		}
+	})
+
+	t.Run("Honours the context", func(t *testing.T) {
+		_, db := newFakeSQL(t)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, _, err := NewSQLKeyStore(db)(cancelled, "PORT"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// ConfigValidator can be implemented by a configuration struct, or any nested struct, to validate rules that
+// involve more than one field. Validate is called once the struct's fields have been populated.
+// Return a ConfigError or FieldError (or several using errors.Join) to attribute errors to specific keys.
+type ConfigValidator interface {
+	Validate() error
+}
+
+// ContextConfigValidator is the context aware variant of ConfigValidator. It is called in preference to Validate if
+// a struct implements both.
+type ContextConfigValidator interface {
+	ValidateContext(ctx context.Context) error
+}
+
+// validateStruct calls the struct's Validate method if it has one, merging the result into the collected errors.
+// fieldKeys maps the struct's field names to their keys. Errors not attributed to a key are reported against
+// the struct's field path.
+func validateStruct(ctx context.Context, v reflect.Value, scope structScope, fieldKeys map[string]string, errs *ConfigErrors) {
+	target := v.Interface()
+	if v.CanAddr() {
+		target = v.Addr().Interface()
+	}
+
+	var err error
+	switch validator := target.(type) {
+	case ContextConfigValidator:
+		err = validator.ValidateContext(ctx)
+	case ConfigValidator:
+		err = validator.Validate()
+	default:
+		return
+	}
+	if err == nil {
+		return
+	}
+
+	structKey := scope.fieldPath
+	if structKey == "" {
+		structKey = v.Type().Name()
+	}
+	addValidationErrors(err, fieldKeys, structKey, errs)
+}
+
+// addValidationErrors adds an error returned from a Validate method, unpacking joined and collected errors.
+func addValidationErrors(err error, fieldKeys map[string]string, structKey string, errs *ConfigErrors) {
+	if configErrs, ok := err.(*ConfigErrors); ok {
+		errs.Errors = append(errs.Errors, configErrs.Errors...)
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			addValidationErrors(e, fieldKeys, structKey, errs)
+		}
+		return
+	}
+
+	var configErr ConfigError
+	var fieldErr *fieldError
+	switch {
+	case errors.As(err, &configErr):
+		errs.Add(configErr.Key, configErr.Err)
+	case errors.As(err, &fieldErr):
+		key, ok := fieldKeys[fieldErr.Field]
+		if !ok {
+			key = structKey + "." + fieldErr.Field
+		}
+		errs.Add(key, fieldErr.Err)
+	default:
+		errs.Add(structKey, err)
+	}
+}
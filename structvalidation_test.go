@@ -0,0 +1,133 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type poolConfig struct {
+	MinConns int `key:"MIN_CONNS"`
+	MaxConns int `key:"MAX_CONNS"`
+}
+
+func (p *poolConfig) Validate() error {
+	if p.MaxConns < p.MinConns {
+		return FieldError("MaxConns", errors.New("must not be less than MIN_CONNS"))
+	}
+	return nil
+}
+
+type tlsConfig struct {
+	Cert string `key:"TLS_CERT"`
+	Key  string `key:"TLS_KEY"`
+}
+
+func (c tlsConfig) ValidateContext(ctx context.Context) error {
+	if ctx.Value(testContextKey{}) != "present" {
+		return errors.New("context not passed")
+	}
+	if c.Cert != "" && c.Key == "" {
+		return errors.Join(
+			ConfigError{Key: "TLS_KEY", Err: errors.New("required when TLS_CERT is set")},
+			errors.New("incomplete TLS configuration"),
+		)
+	}
+	return nil
+}
+
+type serverConfig struct {
+	Primary poolConfig
+	Replica *poolConfig `prefix:"REPLICA_"`
+	TLS     tlsConfig
+	Port    int `key:"PORT"`
+}
+
+var serverValidateCalls int
+
+func (s *serverConfig) Validate() error {
+	serverValidateCalls++
+	if s.Port == 0 {
+		return &ConfigErrors{Errors: []ConfigError{{Key: "PORT", Err: ErrMissingValue}}}
+	}
+	return nil
+}
+
+type testContextKey struct{}
+
+func TestLoad_ValidateHooks(t *testing.T) {
+	ctx := context.WithValue(context.Background(), testContextKey{}, "present")
+
+	load := func(values map[string]string) (*serverConfig, error) {
+		mockStore := func(ctx context.Context, key string) (string, bool, error) {
+			val, ok := values[key]
+			return val, ok, nil
+		}
+		var cfg serverConfig
+		err := Load(ctx, &cfg, WithKeyStore(mockStore))
+		return &cfg, err
+	}
+
+	keysOf := func(err error) []string {
+		var cfgErrs *ConfigErrors
+		if !errors.As(err, &cfgErrs) {
+			t.Fatalf("Expected ConfigErrors, got %v", err)
+		}
+		var keys []string
+		for _, e := range cfgErrs.Errors {
+			keys = append(keys, e.Key)
+		}
+		return keys
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		_, err := load(map[string]string{"PORT": "80", "MIN_CONNS": "1", "MAX_CONNS": "2"})
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+	})
+
+	t.Run("Errors from nested structs use the field keys", func(t *testing.T) {
+		_, err := load(map[string]string{
+			"PORT":              "80",
+			"REPLICA_MIN_CONNS": "5",
+			"REPLICA_MAX_CONNS": "1",
+			"TLS_CERT":          "cert.pem",
+		})
+		got := keysOf(err)
+		want := []string{"REPLICA_MAX_CONNS", "TLS_KEY", "TLS"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Error keys %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Parent validates after nested structs", func(t *testing.T) {
+		_, err := load(map[string]string{})
+		if got := keysOf(err); !reflect.DeepEqual(got, []string{"PORT"}) {
+			t.Errorf("Error keys %v", got)
+		}
+	})
+
+	t.Run("Not called when fields have errors", func(t *testing.T) {
+		serverValidateCalls = 0
+		_, err := load(map[string]string{"PORT": "x", "MIN_CONNS": "x"})
+		if got := keysOf(err); !reflect.DeepEqual(got, []string{"MIN_CONNS", "PORT"}) {
+			t.Errorf("Error keys %v", got)
+		}
+		if serverValidateCalls != 0 {
+			t.Errorf("Validate called %d times", serverValidateCalls)
+		}
+	})
+}
+
+func TestConfigError(t *testing.T) {
+	inner := errors.New("bad")
+	var err error = ConfigError{Key: "PORT", Err: inner}
+	if err.Error() != "PORT: bad" {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, inner) {
+		t.Error("Expected ConfigError to unwrap")
+	}
+}
@@ -0,0 +1,713 @@
+package goconfig
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// NewTOMLKeyStore reads a TOML document and returns a KeyStore for its values. Tables are flattened so that port in
+// the [database] table is named database.port, and by default mapped with EnvStyleKeys to DATABASE_PORT.
+// Use WithKeyMapping to change this.
+//
+// As with NewJSONKeyStore, arrays and tables are also served as JSON. Offset date-times are served in RFC 3339 form,
+// which time.Time fields accept. Local date-times, dates and times have no time zone, so time.Time rejects them. They
+// are served as written, with a T between the date and time, for string fields or custom types.
+func NewTOMLKeyStore(filename string, options ...FileStoreOption) (KeyStore, error) {
+	return loadFileKeyStore(filename, parseTOMLDocument, EnvStyleKeys, options)
+}
+
+// parseTOMLDocument parses a TOML document and flattens it.
+func parseTOMLDocument(filename string, content string) ([]fileEntry, error) {
+	p := &tomlParser{
+		filename: filename,
+		input:    strings.ReplaceAll(content, "\r\n", "\n"),
+		line:     1,
+		column:   1,
+		root:     newTOMLTable(),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return flattenDocument(p.root.toDocument())
+}
+
+// tomlTable is a table being built. Tables are tracked separately from maps to enforce the rules about where a
+// table may be defined or extended.
+type tomlTable struct {
+	values map[string]any
+	// defined is set once the table has a [header], so it cannot have another
+	defined bool
+	// dotted is set if the table was created by a dotted key, so it cannot later have a [header]
+	dotted bool
+	// inline tables are complete as written and cannot be extended
+	inline bool
+}
+
+// tomlTableArray is an array of tables built by [[header]] sections.
+type tomlTableArray struct {
+	tables []*tomlTable
+}
+
+func newTOMLTable() *tomlTable {
+	return &tomlTable{values: make(map[string]any)}
+}
+
+// toDocument converts the table to the form used by flattenDocument.
+func (t *tomlTable) toDocument() map[string]any {
+	document := make(map[string]any, len(t.values))
+	for name, value := range t.values {
+		document[name] = tomlToDocument(value)
+	}
+	return document
+}
+
+func tomlToDocument(value any) any {
+	switch v := value.(type) {
+	case *tomlTable:
+		return v.toDocument()
+	case *tomlTableArray:
+		result := make([]any, len(v.tables))
+		for i, table := range v.tables {
+			result[i] = table.toDocument()
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = tomlToDocument(item)
+		}
+		return result
+	}
+	return value
+}
+
+// tomlParser is a recursive descent parser for TOML 1.0.
+type tomlParser struct {
+	filename string
+	input    string
+	pos      int
+	line     int
+	column   int
+	root     *tomlTable
+	current  *tomlTable
+}
+
+func (p *tomlParser) errorf(msg string) error {
+	return &SyntaxError{File: p.filename, Line: p.line, Column: p.column, Msg: msg}
+}
+
+func (p *tomlParser) atEnd() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.atEnd() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *tomlParser) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(p.input[p.pos:], prefix)
+}
+
+func (p *tomlParser) next() byte {
+	c := p.input[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+		p.column = 1
+	} else {
+		p.column++
+	}
+	return c
+}
+
+func (p *tomlParser) advance(n int) {
+	for i := 0; i < n; i++ {
+		p.next()
+	}
+}
+
+func (p *tomlParser) skipSpaces() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.next()
+	}
+}
+
+// skipSpacesCommentsAndNewlines skips everything that may separate array items.
+func (p *tomlParser) skipSpacesCommentsAndNewlines() {
+	for {
+		p.skipSpaces()
+		switch p.peek() {
+		case '#':
+			p.skipComment()
+		case '\n':
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) skipComment() {
+	for !p.atEnd() && p.peek() != '\n' {
+		p.next()
+	}
+}
+
+// expectEndOfLine allows only whitespace and a comment before the end of the line.
+func (p *tomlParser) expectEndOfLine() error {
+	p.skipSpaces()
+	if p.peek() == '#' {
+		p.skipComment()
+	}
+	if p.atEnd() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("expected end of line")
+	}
+	p.next()
+	return nil
+}
+
+func (p *tomlParser) parse() error {
+	p.current = p.root
+	for {
+		p.skipSpacesCommentsAndNewlines()
+		if p.atEnd() {
+			return nil
+		}
+
+		var err error
+		switch {
+		case p.hasPrefix("[["):
+			err = p.parseTableArrayHeader()
+		case p.peek() == '[':
+			err = p.parseTableHeader()
+		default:
+			err = p.parseKeyValue(p.current)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.expectEndOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseTableHeader parses [a.b.c] and makes it the current table.
+func (p *tomlParser) parseTableHeader() error {
+	p.next()
+	p.skipSpaces()
+	line, column := p.line, p.column
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+	if p.peek() != ']' {
+		return p.errorf("expected ] to close table header")
+	}
+	p.next()
+
+	parent, err := p.walkTables(p.root, keys[:len(keys)-1], false, line, column)
+	if err != nil {
+		return err
+	}
+	name := keys[len(keys)-1]
+	redefined := &SyntaxError{File: p.filename, Line: line, Column: column, Msg: "table " + strings.Join(keys, ".") + " is already defined"}
+	switch existing := parent.values[name].(type) {
+	case nil:
+		table := newTOMLTable()
+		table.defined = true
+		parent.values[name] = table
+		p.current = table
+	case *tomlTable:
+		if existing.defined || existing.dotted || existing.inline {
+			return redefined
+		}
+		existing.defined = true
+		p.current = existing
+	default:
+		return redefined
+	}
+	return nil
+}
+
+// parseTableArrayHeader parses [[a.b]], adding a new table to the array and making it the current table.
+func (p *tomlParser) parseTableArrayHeader() error {
+	p.advance(2)
+	p.skipSpaces()
+	line, column := p.line, p.column
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+	if !p.hasPrefix("]]") {
+		return p.errorf("expected ]] to close array of tables header")
+	}
+	p.advance(2)
+
+	parent, err := p.walkTables(p.root, keys[:len(keys)-1], false, line, column)
+	if err != nil {
+		return err
+	}
+	name := keys[len(keys)-1]
+	table := newTOMLTable()
+	table.defined = true
+	switch existing := parent.values[name].(type) {
+	case nil:
+		parent.values[name] = &tomlTableArray{tables: []*tomlTable{table}}
+	case *tomlTableArray:
+		existing.tables = append(existing.tables, table)
+	default:
+		return &SyntaxError{File: p.filename, Line: line, Column: column, Msg: strings.Join(keys, ".") + " is not an array of tables"}
+	}
+	p.current = table
+	return nil
+}
+
+// walkTables follows the keys from the table, creating tables as needed. The last table of an array of tables is
+// used, as that is the one being built. Dotted keys mark the tables they create.
+// Errors are reported at the given position, which is the start of the key.
+func (p *tomlParser) walkTables(table *tomlTable, keys []string, dotted bool, line, column int) (*tomlTable, error) {
+	errorf := func(msg string) error {
+		return &SyntaxError{File: p.filename, Line: line, Column: column, Msg: msg}
+	}
+	for i, key := range keys {
+		switch existing := table.values[key].(type) {
+		case nil:
+			child := newTOMLTable()
+			child.dotted = dotted
+			table.values[key] = child
+			table = child
+		case *tomlTable:
+			if existing.inline || (dotted && existing.defined) {
+				return nil, errorf("cannot extend table " + strings.Join(keys[:i+1], "."))
+			}
+			table = existing
+		case *tomlTableArray:
+			if dotted {
+				return nil, errorf("cannot extend array of tables " + strings.Join(keys[:i+1], "."))
+			}
+			table = existing.tables[len(existing.tables)-1]
+		default:
+			return nil, errorf(strings.Join(keys[:i+1], ".") + " is not a table")
+		}
+	}
+	return table, nil
+}
+
+// parseKeyValue parses key = value into the table.
+func (p *tomlParser) parseKeyValue(table *tomlTable) error {
+	line, column := p.line, p.column
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+	if p.peek() != '=' {
+		return p.errorf("expected = after key " + strings.Join(keys, "."))
+	}
+	p.next()
+	p.skipSpaces()
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	target, err := p.walkTables(table, keys[:len(keys)-1], true, line, column)
+	if err != nil {
+		return err
+	}
+	name := keys[len(keys)-1]
+	if _, exists := target.values[name]; exists {
+		return &SyntaxError{File: p.filename, Line: line, Column: column, Msg: "duplicate key " + strings.Join(keys, ".")}
+	}
+	target.values[name] = value
+	return nil
+}
+
+// parseKey parses a dotted key made of bare and quoted keys.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		key, err := p.parseSimpleKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+
+		p.skipSpaces()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.next()
+		p.skipSpaces()
+	}
+}
+
+func (p *tomlParser) parseSimpleKey() (string, error) {
+	switch p.peek() {
+	case '"':
+		if p.hasPrefix(`"""`) {
+			return "", p.errorf("multi-line strings cannot be keys")
+		}
+		return p.parseBasicString()
+	case '\'':
+		if p.hasPrefix("'''") {
+			return "", p.errorf("multi-line strings cannot be keys")
+		}
+		return p.parseLiteralString()
+	}
+
+	start := p.pos
+	for !p.atEnd() && isTOMLBareKeyChar(p.peek()) {
+		p.next()
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a key")
+	}
+	return p.input[start:p.pos], nil
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// parseValue parses any value. Numbers are returned as json.Number and dates as strings.
+func (p *tomlParser) parseValue() (any, error) {
+	switch c := p.peek(); {
+	case p.hasPrefix(`"""`):
+		return p.parseMultilineBasicString()
+	case c == '"':
+		return p.parseBasicString()
+	case p.hasPrefix("'''"):
+		return p.parseMultilineLiteralString()
+	case c == '\'':
+		return p.parseLiteralString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case p.hasPrefix("true") && !isTOMLBareKeyChar(p.peekAt(4)):
+		p.advance(4)
+		return true, nil
+	case p.hasPrefix("false") && !isTOMLBareKeyChar(p.peekAt(5)):
+		p.advance(5)
+		return false, nil
+	case c == 0 || c == '\n' || c == '#':
+		return nil, p.errorf("expected a value")
+	}
+	return p.parseNumberOrDate()
+}
+
+func (p *tomlParser) peekAt(offset int) byte {
+	if p.pos+offset >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos+offset]
+}
+
+var (
+	tomlDecimalInt = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	tomlPrefixInt  = regexp.MustCompile(`^(0x[0-9A-Fa-f](_?[0-9A-Fa-f])*|0o[0-7](_?[0-7])*|0b[01](_?[01])*)$`)
+	tomlFloat      = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)((\.[0-9](_?[0-9])*)([eE][+-]?[0-9](_?[0-9])*)?|[eE][+-]?[0-9](_?[0-9])*)$`)
+	tomlSpecial    = regexp.MustCompile(`^[+-]?(inf|nan)$`)
+	tomlDateTime   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})?)?$|^\d{2}:\d{2}:\d{2}(\.\d+)?$`)
+)
+
+// parseNumberOrDate parses integers, floats and dates. Integers are normalised to decimal.
+// Infinity and NaN are returned as strings, as JSON cannot represent them.
+func (p *tomlParser) parseNumberOrDate() (any, error) {
+	line, column := p.line, p.column
+	start := p.pos
+	for !p.atEnd() && isTOMLValueChar(p.peek()) {
+		p.next()
+	}
+	// A space may separate the date and time
+	if p.peek() == ' ' && tomlDateTime.MatchString(p.input[start:p.pos]) && len(p.input)-p.pos > 3 &&
+		isDigit(p.peekAt(1)) && isDigit(p.peekAt(2)) && p.peekAt(3) == ':' {
+		p.next()
+		for !p.atEnd() && isTOMLValueChar(p.peek()) {
+			p.next()
+		}
+	}
+	token := p.input[start:p.pos]
+	invalid := func(msg string) error {
+		return &SyntaxError{File: p.filename, Line: line, Column: column, Msg: msg}
+	}
+
+	switch {
+	case tomlDecimalInt.MatchString(token), tomlPrefixInt.MatchString(token):
+		value, err := strconv.ParseInt(token, 0, 64)
+		if err != nil {
+			return nil, invalid("integer out of range")
+		}
+		return json.Number(strconv.FormatInt(value, 10)), nil
+	case tomlFloat.MatchString(token):
+		return json.Number(strings.TrimPrefix(strings.ReplaceAll(token, "_", ""), "+")), nil
+	case tomlSpecial.MatchString(token):
+		return token, nil
+	case tomlDateTime.MatchString(token):
+		return normaliseTOMLDateTime(token, invalid)
+	}
+	return nil, invalid("invalid value")
+}
+
+// normaliseTOMLDateTime checks the date or time and returns it in RFC 3339 form.
+func normaliseTOMLDateTime(token string, invalid func(string) error) (any, error) {
+	value := strings.ToUpper(token)
+	if len(value) > 10 && value[10] == ' ' {
+		value = value[:10] + "T" + value[11:]
+	}
+
+	layout := "15:04:05"
+	switch {
+	case len(value) == 10:
+		layout = time.DateOnly
+	case strings.Contains(value, "T") && (strings.HasSuffix(value, "Z") || strings.ContainsAny(value[10:], "+-")):
+		layout = time.RFC3339
+	case strings.Contains(value, "T"):
+		layout = "2006-01-02T15:04:05"
+	}
+	if _, err := time.Parse(layout, value); err != nil {
+		return nil, invalid("invalid date or time")
+	}
+	return value, nil
+}
+
+func isTOMLValueChar(c byte) bool {
+	return isTOMLBareKeyChar(c) || c == '+' || c == '.' || c == ':'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseArray parses [a, b, c]. Arrays may span lines and contain comments.
+func (p *tomlParser) parseArray() (any, error) {
+	p.next()
+	items := []any{}
+	for {
+		p.skipSpacesCommentsAndNewlines()
+		if p.peek() == ']' {
+			p.next()
+			return items, nil
+		}
+
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		p.skipSpacesCommentsAndNewlines()
+		switch p.peek() {
+		case ',':
+			p.next()
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+// parseInlineTable parses { a = 1, b.c = 2 }, which must be on one line.
+func (p *tomlParser) parseInlineTable() (any, error) {
+	p.next()
+	table := newTOMLTable()
+	p.skipSpaces()
+	if p.peek() == '}' {
+		p.next()
+		table.inline = true
+		return table, nil
+	}
+
+	for {
+		p.skipSpaces()
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.next()
+		case '}':
+			p.next()
+			table.inline = true
+			return table, nil
+		default:
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// parseBasicString parses "..." with escapes.
+func (p *tomlParser) parseBasicString() (string, error) {
+	line, column := p.line, p.column
+	p.next()
+	var result strings.Builder
+	for {
+		switch c := p.peek(); {
+		case p.atEnd(), c == '\n':
+			return "", &SyntaxError{File: p.filename, Line: line, Column: column, Msg: "unterminated string"}
+		case c == '"':
+			p.next()
+			return result.String(), nil
+		case c == '\\':
+			if err := p.parseEscape(&result); err != nil {
+				return "", err
+			}
+		default:
+			result.WriteByte(p.next())
+		}
+	}
+}
+
+// parseMultilineBasicString parses """...""" with escapes. A newline straight after the opening quotes is removed,
+// as is a backslash at the end of a line along with the whitespace that follows.
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	line, column := p.line, p.column
+	p.advance(3)
+	if p.peek() == '\n' {
+		p.next()
+	}
+
+	var result strings.Builder
+	for {
+		switch c := p.peek(); {
+		case p.atEnd():
+			return "", &SyntaxError{File: p.filename, Line: line, Column: column, Msg: "unterminated string"}
+		case p.hasPrefix(`"""`):
+			p.closeMultilineString('"', &result)
+			return result.String(), nil
+		case c == '\\' && p.isLineEndingBackslash():
+			p.next()
+			for p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\n' {
+				p.next()
+			}
+		case c == '\\':
+			if err := p.parseEscape(&result); err != nil {
+				return "", err
+			}
+		default:
+			result.WriteByte(p.next())
+		}
+	}
+}
+
+// isLineEndingBackslash reports whether the backslash at the current position is followed only by whitespace
+// before the end of the line.
+func (p *tomlParser) isLineEndingBackslash() bool {
+	for i := p.pos + 1; i < len(p.input); i++ {
+		switch p.input[i] {
+		case ' ', '\t':
+			continue
+		case '\n':
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// closeMultilineString consumes the closing quotes. Up to two quotes before them belong to the string.
+func (p *tomlParser) closeMultilineString(quote byte, result *strings.Builder) {
+	count := 0
+	for p.peekAt(count) == quote {
+		count++
+	}
+	for i := 0; i < min(count-3, 2); i++ {
+		result.WriteByte(quote)
+	}
+	p.advance(min(count, 5))
+}
+
+// parseLiteralString parses '...' without escapes.
+func (p *tomlParser) parseLiteralString() (string, error) {
+	line, column := p.line, p.column
+	p.next()
+	start := p.pos
+	for !p.atEnd() && p.peek() != '\'' && p.peek() != '\n' {
+		p.next()
+	}
+	if p.peek() != '\'' {
+		return "", &SyntaxError{File: p.filename, Line: line, Column: column, Msg: "unterminated string"}
+	}
+	value := p.input[start:p.pos]
+	p.next()
+	return value, nil
+}
+
+// parseMultilineLiteralString parses a string in triple single quotes, without escapes.
+func (p *tomlParser) parseMultilineLiteralString() (string, error) {
+	line, column := p.line, p.column
+	p.advance(3)
+	if p.peek() == '\n' {
+		p.next()
+	}
+
+	var result strings.Builder
+	for {
+		if p.atEnd() {
+			return "", &SyntaxError{File: p.filename, Line: line, Column: column, Msg: "unterminated string"}
+		}
+		if p.hasPrefix("'''") {
+			p.closeMultilineString('\'', &result)
+			return result.String(), nil
+		}
+		result.WriteByte(p.next())
+	}
+}
+
+// parseEscape parses a backslash escape sequence in a basic string.
+func (p *tomlParser) parseEscape(result *strings.Builder) error {
+	line, column := p.line, p.column
+	invalid := &SyntaxError{File: p.filename, Line: line, Column: column, Msg: "invalid escape sequence"}
+	p.next()
+	if p.atEnd() {
+		return invalid
+	}
+
+	switch c := p.next(); c {
+	case 'b':
+		result.WriteByte('\b')
+	case 't':
+		result.WriteByte('\t')
+	case 'n':
+		result.WriteByte('\n')
+	case 'f':
+		result.WriteByte('\f')
+	case 'r':
+		result.WriteByte('\r')
+	case '"', '\\':
+		result.WriteByte(c)
+	case 'u', 'U':
+		digits := 4
+		if c == 'U' {
+			digits = 8
+		}
+		if len(p.input)-p.pos < digits {
+			return invalid
+		}
+		code, err := strconv.ParseUint(p.input[p.pos:p.pos+digits], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return invalid
+		}
+		p.advance(digits)
+		result.WriteRune(rune(code))
+	default:
+		return invalid
+	}
+	return nil
+}
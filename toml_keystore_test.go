@@ -0,0 +1,171 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTOMLDocument(t *testing.T) {
+	document := `# A comment
+title = "TOML \"example\" \u00e9" # trailing comment
+"quoted key" = 'C:\path'
+bare-key_1 = 1
+
+[database]
+ports = [ 8000, 8001,
+  # comment in array
+  8002, ]
+enabled = true
+temp_targets = { cpu = 79.5, case.max = 72.0 }
+hex = 0xDEAD_BEEF
+octal = 0o17
+binary = -0
+float = +6.626e-34
+big = 1_000_000
+infinity = -inf
+created = 1979-05-27 07:32:00Z
+local = 1979-05-27T07:32:00.999
+date = 1979-05-27
+time = 07:32:00
+
+[servers.alpha]
+ip = "10.0.0.1"
+site."google.com" = true
+
+[[products]]
+name = "Hammer"
+
+[[products]]
+name = "Nail"
+
+[text]
+multi = """
+Roses are red
+Violets are \
+    blue"""
+literal = '''
+raw \n text'''
+quotes = """a "quoted" word"""""
+`
+
+	entries, err := parseTOMLDocument("test.toml", document)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got := make(map[string]string)
+	for _, entry := range entries {
+		got[entry.Key] = entry.Value
+	}
+
+	want := map[string]string{
+		"title":                          `TOML "example" é`,
+		"quoted key":                     `C:\path`,
+		"bare-key_1":                     "1",
+		"database.ports":                 "[8000,8001,8002]",
+		"database.enabled":               "true",
+		"database.temp_targets":          `{"case":{"max":72.0},"cpu":79.5}`,
+		"database.temp_targets.cpu":      "79.5",
+		"database.temp_targets.case":     `{"max":72.0}`,
+		"database.temp_targets.case.max": "72.0",
+		"database.hex":                   "3735928559",
+		"database.octal":                 "15",
+		"database.binary":                "0",
+		"database.float":                 "6.626e-34",
+		"database.big":                   "1000000",
+		"database.infinity":              "-inf",
+		"database.created":               "1979-05-27T07:32:00Z",
+		"database.local":                 "1979-05-27T07:32:00.999",
+		"database.date":                  "1979-05-27",
+		"database.time":                  "07:32:00",
+		"servers.alpha.ip":               "10.0.0.1",
+		"servers.alpha.site.google.com":  "true",
+		"products":                       `[{"name":"Hammer"},{"name":"Nail"}]`,
+		"text.multi":                     "Roses are red\nViolets are blue",
+		"text.literal":                   `raw \n text`,
+		"text.quotes":                    `a "quoted" word""`,
+	}
+	for key, wantValue := range want {
+		if got[key] != wantValue {
+			t.Errorf("%s = %q, want %q", key, got[key], wantValue)
+		}
+	}
+}
+
+func TestParseTOMLDocument_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr SyntaxError
+	}{
+		{"missing equals", "a 1", SyntaxError{Line: 1, Column: 3, Msg: "expected = after key a"}},
+		{"duplicate key", "a = 1\na = 2", SyntaxError{Line: 2, Column: 1, Msg: "duplicate key a"}},
+		{"duplicate table", "[a]\n[b]\n[a]", SyntaxError{Line: 3, Column: 2, Msg: "table a is already defined"}},
+		{"table after dotted key", "a.b = 1\n[a]", SyntaxError{Line: 2, Column: 2, Msg: "table a is already defined"}},
+		{"extend inline table", "a = {}\n[a.b]", SyntaxError{Line: 2, Column: 2, Msg: "cannot extend table a"}},
+		{"static array as table array", "a = []\n[[a]]", SyntaxError{Line: 2, Column: 3, Msg: "a is not an array of tables"}},
+		{"unterminated string", "a = \"abc\nb = 1", SyntaxError{Line: 1, Column: 5, Msg: "unterminated string"}},
+		{"unterminated multiline", "a = '''abc", SyntaxError{Line: 1, Column: 5, Msg: "unterminated string"}},
+		{"invalid escape", `a = "\x"`, SyntaxError{Line: 1, Column: 6, Msg: "invalid escape sequence"}},
+		{"leading zero", "a = 012", SyntaxError{Line: 1, Column: 5, Msg: "invalid value"}},
+		{"bad underscore", "a = 1__0", SyntaxError{Line: 1, Column: 5, Msg: "invalid value"}},
+		{"out of range", "a = 9223372036854775808", SyntaxError{Line: 1, Column: 5, Msg: "integer out of range"}},
+		{"invalid date", "a = 1979-13-27", SyntaxError{Line: 1, Column: 5, Msg: "invalid date or time"}},
+		{"missing value", "a =\n", SyntaxError{Line: 1, Column: 4, Msg: "expected a value"}},
+		{"text after value", "a = 1 b = 2", SyntaxError{Line: 1, Column: 7, Msg: "expected end of line"}},
+		{"unclosed array", "a = [1, 2\nb = 1", SyntaxError{Line: 2, Column: 1, Msg: "expected , or ] in array"}},
+		{"newline in inline table", "a = { b = 1,\n c = 2 }", SyntaxError{Line: 1, Column: 13, Msg: "expected a key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOMLDocument("test.toml", tt.input)
+			tt.wantErr.File = "test.toml"
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) || *syntaxErr != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", &tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewTOMLKeyStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+[database]
+port = 5432
+max_conns = 10
+created = 2025-01-02T03:04:05Z
+hosts = ["a", "b"]
+`
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewTOMLKeyStore(filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	type Config struct {
+		Database struct {
+			Port     int
+			MaxConns int
+			Created  time.Time
+			Hosts    []string `format:"json"`
+		}
+	}
+	var cfg Config
+	if err := Load(context.Background(), &cfg, WithKeyStore(store), WithKeyNaming(SnakeCaseKeys)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Database.Port != 5432 || cfg.Database.MaxConns != 10 ||
+		!cfg.Database.Created.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) ||
+		!reflect.DeepEqual(cfg.Database.Hosts, []string{"a", "b"}) {
+		t.Errorf("Got %+v", cfg)
+	}
+}
@@ -0,0 +1,91 @@
+package goconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValidatorFactory returns validators for a field given its struct field definition and its path
+// (for example "Database.Port"). Return nil if the factory has nothing to add for the field.
+// An error fails the Load immediately, as it indicates a problem with the configuration struct rather than its values.
+type ValidatorFactory func(field reflect.StructField, path string) ([]Validator[any], error)
+
+// validatorsFor gathers the path validators and factory validators for a field.
+// The validators are adapted to receive the value converted to the field's type.
+func (opts *loadOptions) validatorsFor(field reflect.StructField, path string) ([]Validator[any], error) {
+	validators := append([]Validator[any]{}, opts.validators[path]...)
+	for _, factory := range opts.validatorFactories {
+		contributed, err := factory(field, path)
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, contributed...)
+	}
+	if len(validators) == 0 {
+		return nil, nil
+	}
+
+	valueType := field.Type
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+
+	adapted := make([]Validator[any], len(validators))
+	for i, validator := range validators {
+		adapted[i] = func(value any) error {
+			return validator(convertToFieldType(value, valueType))
+		}
+	}
+	return adapted, nil
+}
+
+// convertToFieldType converts a pipeline output to the field's own type where possible.
+// Built-in handlers work in the widest type of a kind (int64 for all integers) so this lets validators use
+// a type assertion on the field's declared type.
+func convertToFieldType(value any, valueType reflect.Type) any {
+	val := reflect.ValueOf(value)
+	if val.IsValid() && val.Type() != valueType && val.Type().ConvertibleTo(valueType) {
+		return val.Convert(valueType).Interface()
+	}
+	return value
+}
+
+// checkValidatorPaths makes sure every path given to WithValidator names a field in the config struct.
+func (opts *loadOptions) checkValidatorPaths(configType reflect.Type) error {
+	for path := range opts.validators {
+		if !hasFieldPath(configType, path) {
+			return fmt.Errorf("validator registered for unknown field %s", path)
+		}
+	}
+	return nil
+}
+
+// hasFieldPath reports whether the dotted path of field names exists in the struct type.
+func hasFieldPath(t reflect.Type, path string) bool {
+	for _, name := range strings.Split(path, ".") {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		// FieldByName would also find promoted fields, but Load addresses embedded fields by their type name
+		field, ok := directField(t, name)
+		if !ok {
+			return false
+		}
+		t = field.Type
+	}
+	return true
+}
+
+// directField finds a field declared directly on the struct type.
+func directField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Name == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,148 @@
+package goconfig
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithValidator(t *testing.T) {
+	type Database struct {
+		Port int `key:"DB_PORT"`
+	}
+	type Config struct {
+		APIKey   string `key:"API_KEY"`
+		Database *Database
+	}
+
+	mockStore := func(ctx context.Context, key string) (string, bool, error) {
+		switch key {
+		case "API_KEY":
+			return "sk-123", true, nil
+		case "DB_PORT":
+			return "5432", true, nil
+		}
+		return "", false, nil
+	}
+
+	t.Run("Validators receive the field type", func(t *testing.T) {
+		var gotKey string
+		var gotPort int
+		var cfg Config
+		err := Load(context.Background(), &cfg, WithKeyStore(mockStore),
+			WithValidator("APIKey", func(value any) error {
+				gotKey = value.(string)
+				return nil
+			}),
+			WithValidator("Database.Port", func(value any) error {
+				gotPort = value.(int)
+				return nil
+			}))
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if gotKey != "sk-123" || gotPort != 5432 {
+			t.Errorf("Validators saw %q and %d", gotKey, gotPort)
+		}
+	})
+
+	t.Run("Validation errors are reported against the key", func(t *testing.T) {
+		var cfg Config
+		err := Load(context.Background(), &cfg, WithKeyStore(mockStore),
+			WithValidator("Database.Port", func(value any) error {
+				return errors.New("port not allowed")
+			}),
+			WithValidator("Database.Port", func(value any) error {
+				t.Error("Second validator should not run after a failure")
+				return nil
+			}))
+		var cfgErrs *ConfigErrors
+		if !errors.As(err, &cfgErrs) {
+			t.Fatalf("Expected ConfigErrors, got %v", err)
+		}
+		if cfgErrs.Len() != 1 || cfgErrs.Errors[0].Key != "DB_PORT" {
+			t.Errorf("Unexpected errors: %v", err)
+		}
+		if cfg.Database.Port != 0 {
+			t.Errorf("Field should not be set when validation fails, got %d", cfg.Database.Port)
+		}
+	})
+
+	t.Run("Unknown path", func(t *testing.T) {
+		var cfg Config
+		err := Load(context.Background(), &cfg, WithKeyStore(mockStore),
+			WithValidator("Database.Host", func(value any) error { return nil }))
+		if err == nil || !strings.Contains(err.Error(), "unknown field Database.Host") {
+			t.Errorf("Expected unknown field error, got %v", err)
+		}
+	})
+}
+
+func TestWithValidatorFactory(t *testing.T) {
+	type Config struct {
+		Name  string `key:"NAME" notBlank:"true"`
+		Other string `key:"OTHER"`
+		Port  *int   `key:"PORT"`
+	}
+
+	mockStore := func(ctx context.Context, key string) (string, bool, error) {
+		switch key {
+		case "NAME":
+			return " ", true, nil
+		case "OTHER":
+			return " ", true, nil
+		case "PORT":
+			return "80", true, nil
+		}
+		return "", false, nil
+	}
+
+	var paths []string
+	factory := func(field reflect.StructField, path string) ([]Validator[any], error) {
+		paths = append(paths, path)
+		var validators []Validator[any]
+		if field.Tag.Get("notBlank") == "true" {
+			validators = append(validators, func(value any) error {
+				if strings.TrimSpace(value.(string)) == "" {
+					return errors.New("must not be blank")
+				}
+				return nil
+			})
+		}
+		if field.Type == reflect.TypeOf((*int)(nil)) {
+			validators = append(validators, func(value any) error {
+				if value.(int) < 1024 {
+					return errors.New("privileged port")
+				}
+				return nil
+			})
+		}
+		return validators, nil
+	}
+
+	var cfg Config
+	err := Load(context.Background(), &cfg, WithKeyStore(mockStore), WithValidatorFactory(factory))
+	var cfgErrs *ConfigErrors
+	if !errors.As(err, &cfgErrs) {
+		t.Fatalf("Expected ConfigErrors, got %v", err)
+	}
+	if cfgErrs.Len() != 2 || cfgErrs.Errors[0].Key != "NAME" || cfgErrs.Errors[1].Key != "PORT" {
+		t.Errorf("Unexpected errors: %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"Name", "Other", "Port"}) {
+		t.Errorf("Factory called for paths %v", paths)
+	}
+
+	t.Run("Factory error fails fast", func(t *testing.T) {
+		var cfg Config
+		err := Load(context.Background(), &cfg, WithKeyStore(mockStore),
+			WithValidatorFactory(func(field reflect.StructField, path string) ([]Validator[any], error) {
+				return nil, errors.New("bad tag")
+			}))
+		if err == nil || !strings.Contains(err.Error(), "setting up validators for field Name: bad tag") {
+			t.Errorf("Expected setup error, got %v", err)
+		}
+	})
+}
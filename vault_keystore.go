@@ -0,0 +1,428 @@
+package goconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKubernetesTokenPath is where Kubernetes mounts the service account token used by WithVaultKubernetesAuth.
+const DefaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultOption configures NewVaultKeyStore.
+type VaultOption func(*vaultSettings)
+
+type vaultSettings struct {
+	client    *http.Client
+	mount     string
+	namespace string
+	keyPath   func(key string) string
+	token     string
+	login     func(ctx context.Context, v *VaultKeyStore) (*vaultAuth, error)
+}
+
+// WithVaultHTTPClient sets the HTTP client, for example to configure TLS. The default is http.DefaultClient.
+func WithVaultHTTPClient(client *http.Client) VaultOption {
+	return func(s *vaultSettings) { s.client = client }
+}
+
+// WithVaultMount sets the mount path of the KV version 2 secrets engine. The default is "secret".
+func WithVaultMount(mount string) VaultOption {
+	return func(s *vaultSettings) { s.mount = strings.Trim(mount, "/") }
+}
+
+// WithVaultNamespace sets the Vault Enterprise namespace sent with each request.
+func WithVaultNamespace(namespace string) VaultOption {
+	return func(s *vaultSettings) { s.namespace = namespace }
+}
+
+// WithVaultKeyMapping sets how a key is mapped to a secret reference of the form path#field. Return an empty string
+// if the key is not held in Vault. The default uses the key as the reference, so keys are written as
+// key:"myapp/database#password". To read every key from one secret:
+//
+//	goconfig.WithVaultKeyMapping(func(key string) string { return "myapp#" + key })
+func WithVaultKeyMapping(mapping func(key string) string) VaultOption {
+	return func(s *vaultSettings) { s.keyPath = mapping }
+}
+
+// WithVaultToken authenticates with a token. The token is not renewed. The default is the VAULT_TOKEN environment
+// variable.
+func WithVaultToken(token string) VaultOption {
+	return func(s *vaultSettings) {
+		s.token = token
+		s.login = nil
+	}
+}
+
+// WithVaultAppRoleAuth logs in with the AppRole method mounted at auth/approle.
+func WithVaultAppRoleAuth(roleID, secretID string) VaultOption {
+	return func(s *vaultSettings) {
+		s.token = ""
+		s.login = func(ctx context.Context, v *VaultKeyStore) (*vaultAuth, error) {
+			return v.login(ctx, "approle", map[string]string{"role_id": roleID, "secret_id": secretID})
+		}
+	}
+}
+
+// WithVaultKubernetesAuth logs in with the Kubernetes method mounted at auth/kubernetes, using the service account
+// token read from tokenPath. If tokenPath is empty, DefaultKubernetesTokenPath is used. The file is read at each
+// login so that rotated tokens are used.
+func WithVaultKubernetesAuth(role, tokenPath string) VaultOption {
+	if tokenPath == "" {
+		tokenPath = DefaultKubernetesTokenPath
+	}
+	return func(s *vaultSettings) {
+		s.token = ""
+		s.login = func(ctx context.Context, v *VaultKeyStore) (*vaultAuth, error) {
+			jwt, err := os.ReadFile(tokenPath)
+			if err != nil {
+				return nil, fmt.Errorf("reading service account token: %w", err)
+			}
+			return v.login(ctx, "kubernetes", map[string]string{"role": role, "jwt": strings.TrimSpace(string(jwt))})
+		}
+	}
+}
+
+// VaultKeyStore reads keys from the HashiCorp Vault KV version 2 secrets engine. Use its Lookup method as the
+// KeyStore. It is safe for concurrent use.
+type VaultKeyStore struct {
+	address  string
+	settings vaultSettings
+	now      func() time.Time
+
+	mu        sync.Mutex
+	auth      *vaultAuth
+	tokenCall *vaultTokenCall
+}
+
+// vaultTokenCall is a login or renewal in progress, shared by concurrent lookups.
+type vaultTokenCall struct {
+	done chan struct{}
+	auth *vaultAuth
+	err  error
+}
+
+// vaultAuth is the token in use and its lease.
+type vaultAuth struct {
+	token     string
+	renewable bool
+	// ttl is zero if the token does not expire
+	ttl     time.Duration
+	expires time.Time
+}
+
+type vaultCacheKey struct {
+	store *VaultKeyStore
+	path  string
+}
+
+// NewVaultKeyStore returns a store for the Vault server at address, such as https://vault.example.com:8200.
+// If address is empty the VAULT_ADDR environment variable is used.
+//
+// Each key maps to a field of a secret using a reference of the form path#field, read from the latest version of
+// the secret. Fields holding objects can be addressed with a dotted path, as in myapp#database.password, or read
+// whole as JSON. Each secret is fetched once per Load, using the context passed to the KeyStore. Keys whose secret
+// or field does not exist are not present.
+//
+// Tokens obtained by logging in are renewed on use: the first lookup after two thirds of the lease has passed renews
+// the token, and the store logs in again when a token cannot be renewed or is rejected. There is no background
+// renewal. Concurrent lookups share a single login or renewal, and no lock is held while it runs. Error responses are returned as an *HTTPStatusError, with Vault's
+// messages added.
+//
+//	vault := goconfig.NewVaultKeyStore("", goconfig.WithVaultKubernetesAuth("myapp", ""))
+//	err := goconfig.Load(ctx, &cfg, goconfig.WithKeyStore(vault.Lookup))
+func NewVaultKeyStore(address string, options ...VaultOption) *VaultKeyStore {
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	settings := vaultSettings{
+		client:  http.DefaultClient,
+		mount:   "secret",
+		keyPath: func(key string) string { return key },
+		token:   os.Getenv("VAULT_TOKEN"),
+	}
+	for _, opt := range options {
+		opt(&settings)
+	}
+	return &VaultKeyStore{
+		address:  strings.TrimSuffix(address, "/"),
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+// Lookup implements KeyStore.
+func (v *VaultKeyStore) Lookup(ctx context.Context, key string) (string, bool, error) {
+	path, field, found := strings.Cut(v.settings.keyPath(key), "#")
+	path = strings.Trim(path, "/")
+	if !found || path == "" || field == "" {
+		return "", false, nil
+	}
+
+	secret, err := fetchOncePerLoad(ctx, vaultCacheKey{store: v, path: path}, func() (any, error) {
+		return v.readSecret(ctx, path)
+	})
+	if err != nil {
+		return "", false, ConfigError{Key: key, Err: err}
+	}
+	value, ok := secret.(map[string]string)[field]
+	return value, ok, nil
+}
+
+// readSecret reads the fields of a secret, flattened in the same way as a JSON document. It returns nil if the
+// secret does not exist.
+func (v *VaultKeyStore) readSecret(ctx context.Context, path string) (map[string]string, error) {
+	var response struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	endpoint := "/v1/" + v.settings.mount + "/data/" + escapePath(path)
+	found, err := v.authenticatedRequest(ctx, http.MethodGet, endpoint, &response)
+	if err != nil {
+		return nil, fmt.Errorf("reading vault secret %s: %w", path, err)
+	}
+	if !found || response.Data.Data == nil {
+		return nil, nil
+	}
+
+	entries, err := flattenDocument(response.Data.Data)
+	if err != nil {
+		return nil, fmt.Errorf("reading vault secret %s: %w", path, err)
+	}
+	fields := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		fields[entry.Key] = entry.Value
+	}
+	return fields, nil
+}
+
+// authenticatedRequest makes a request with the current token, logging in again if the token is rejected.
+// It returns false if the resource was not found.
+func (v *VaultKeyStore) authenticatedRequest(ctx context.Context, method, endpoint string, result any) (bool, error) {
+	token, err := v.token(ctx)
+	if err != nil {
+		return false, err
+	}
+	found, err := v.request(ctx, method, endpoint, token, nil, result)
+	if errors.Is(err, errVaultForbidden) && v.settings.login != nil {
+		v.clearToken(token)
+		if token, err = v.token(ctx); err != nil {
+			return false, err
+		}
+		found, err = v.request(ctx, method, endpoint, token, nil, result)
+	}
+	return found, err
+}
+
+// token returns a usable token, logging in or renewing the lease as needed.
+func (v *VaultKeyStore) token(ctx context.Context) (string, error) {
+	if v.settings.login == nil {
+		if v.settings.token == "" {
+			return "", errors.New("no vault token configured")
+		}
+		return v.settings.token, nil
+	}
+
+	for {
+		v.mu.Lock()
+		if v.auth != nil && !v.needsRefresh(v.auth) {
+			token := v.auth.token
+			v.mu.Unlock()
+			return token, nil
+		}
+		call := v.tokenCall
+		if call == nil {
+			call = &vaultTokenCall{done: make(chan struct{})}
+			v.tokenCall = call
+			current := v.auth
+			v.mu.Unlock()
+			v.refreshToken(ctx, call, current)
+			if call.err != nil {
+				return "", call.err
+			}
+			return call.auth.token, nil
+		}
+		v.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		// The call failed because its caller gave up, which should not affect this caller
+		if isContextError(call.err) && ctx.Err() == nil {
+			continue
+		}
+		if call.err != nil {
+			return "", call.err
+		}
+		return call.auth.token, nil
+	}
+}
+
+// needsRefresh reports whether two thirds of the token's lease has passed.
+func (v *VaultKeyStore) needsRefresh(auth *vaultAuth) bool {
+	return auth.ttl > 0 && !v.now().Before(auth.expires.Add(-auth.ttl/3))
+}
+
+// refreshToken renews the current token if it can, or logs in, without holding the lock during the requests.
+func (v *VaultKeyStore) refreshToken(ctx context.Context, call *vaultTokenCall, current *vaultAuth) {
+	defer close(call.done)
+	if current != nil && current.renewable && v.now().Before(current.expires) {
+		if renewed, err := v.renew(ctx, current.token); err == nil {
+			call.auth = renewed
+		}
+	}
+	if call.auth == nil {
+		if auth, err := v.settings.login(ctx, v); err != nil {
+			call.err = fmt.Errorf("vault login: %w", err)
+		} else {
+			call.auth = auth
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.tokenCall = nil
+	if call.err == nil {
+		v.auth = call.auth
+	}
+}
+
+// clearToken forgets a token that has been rejected, unless another lookup has already replaced it.
+func (v *VaultKeyStore) clearToken(token string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.auth != nil && v.auth.token == token {
+		v.auth = nil
+	}
+}
+
+// login authenticates with the auth method mounted at auth/<method>.
+func (v *VaultKeyStore) login(ctx context.Context, method string, body map[string]string) (*vaultAuth, error) {
+	return v.authRequest(ctx, "/v1/auth/"+method+"/login", "", body)
+}
+
+// renew extends the lease of the token.
+func (v *VaultKeyStore) renew(ctx context.Context, token string) (*vaultAuth, error) {
+	return v.authRequest(ctx, "/v1/auth/token/renew-self", token, map[string]string{})
+}
+
+func (v *VaultKeyStore) authRequest(ctx context.Context, endpoint, token string, body map[string]string) (*vaultAuth, error) {
+	var response struct {
+		Auth *struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+			Renewable     bool   `json:"renewable"`
+		} `json:"auth"`
+	}
+	if _, err := v.request(ctx, http.MethodPost, endpoint, token, body, &response); err != nil {
+		return nil, err
+	}
+	if response.Auth == nil || response.Auth.ClientToken == "" {
+		return nil, errors.New("vault response contains no token")
+	}
+	ttl := time.Duration(response.Auth.LeaseDuration) * time.Second
+	return &vaultAuth{
+		token:     response.Auth.ClientToken,
+		renewable: response.Auth.Renewable,
+		ttl:       ttl,
+		expires:   v.now().Add(ttl),
+	}, nil
+}
+
+var errVaultForbidden = errors.New("permission denied")
+
+// vaultMaxResponseSize limits the size of a successful response, which holds a secret or a token.
+const vaultMaxResponseSize = 1024 * 1024
+
+// request calls the Vault API and decodes the JSON response into result. It returns false for a 404 response.
+func (v *VaultKeyStore) request(ctx context.Context, method, endpoint, token string, body any, result any) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return false, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, v.address+endpoint, reader)
+	if err != nil {
+		return false, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.settings.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.settings.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.settings.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, vaultStatusError(req, resp)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, vaultMaxResponseSize+1))
+	if err != nil {
+		return false, err
+	}
+	if len(content) > vaultMaxResponseSize {
+		return false, fmt.Errorf("vault response is larger than %d bytes", vaultMaxResponseSize)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(result); err != nil {
+		// The decoder's messages can quote the response, which holds secrets
+		return false, errors.New("invalid response from vault")
+	}
+	return true, nil
+}
+
+// vaultStatusError returns an *HTTPStatusError for an error response, wrapped with the messages in its errors array.
+// A 403 Forbidden response also wraps errVaultForbidden so that the token can be replaced.
+func vaultStatusError(req *http.Request, resp *http.Response) error {
+	statusErr := &HTTPStatusError{URL: redactedURL(req.URL), StatusCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %w", statusErr, errVaultForbidden)
+	}
+
+	var response struct {
+		Errors []string `json:"errors"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&response)
+	if len(response.Errors) == 0 {
+		return statusErr
+	}
+	return fmt.Errorf("%w: %s", statusErr, strings.Join(response.Errors, "; "))
+}
+
+// escapePath escapes each segment of a secret path for use in a URL.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,327 @@
+package goconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeVault is a stand-in for the parts of the Vault API used by VaultKeyStore.
+type fakeVault struct {
+	mu        sync.Mutex
+	secrets   map[string]map[string]any
+	tokens    map[string]bool
+	reads     map[string]int
+	logins    []map[string]string
+	renewals  int
+	leaseSecs int64
+	renewable bool
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	fake := &fakeVault{
+		secrets: map[string]map[string]any{
+			"myapp/database": {"username": "app", "password": "s3cret", "port": json.Number("5432"),
+				"tls": map[string]any{"mode": "verify-full"}},
+			"myapp/api": {"API_KEY": "abc123"},
+		},
+		tokens:    map[string]bool{"root": true},
+		reads:     make(map[string]int),
+		leaseSecs: 3600,
+		renewable: true,
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON := func(status int, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+	issueToken := func() {
+		token := "token-" + string(rune('a'+len(f.tokens)))
+		f.tokens[token] = true
+		writeJSON(http.StatusOK, map[string]any{"auth": map[string]any{
+			"client_token": token, "lease_duration": f.leaseSecs, "renewable": f.renewable}})
+	}
+
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/login"):
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.logins = append(f.logins, body)
+		if body["secret_id"] == "wrong" {
+			writeJSON(http.StatusBadRequest, map[string]any{"errors": []string{"invalid role or secret ID"}})
+			return
+		}
+		issueToken()
+		return
+	case !f.tokens[r.Header.Get("X-Vault-Token")]:
+		writeJSON(http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/auth/token/renew-self":
+		f.renewals++
+		writeJSON(http.StatusOK, map[string]any{"auth": map[string]any{
+			"client_token": r.Header.Get("X-Vault-Token"), "lease_duration": f.leaseSecs, "renewable": f.renewable}})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		path := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+		f.reads[path]++
+		if path == "broken" {
+			writeJSON(http.StatusInternalServerError, map[string]any{"errors": []string{"internal error"}})
+			return
+		}
+		data, ok := f.secrets[path]
+		if !ok {
+			writeJSON(http.StatusNotFound, map[string]any{"errors": []string{}})
+			return
+		}
+		writeJSON(http.StatusOK, map[string]any{"data": map[string]any{"data": data, "metadata": map[string]any{"version": 1}}})
+	default:
+		writeJSON(http.StatusNotFound, map[string]any{"errors": []string{}})
+	}
+}
+
+func TestVaultKeyStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Reads fields with a token", func(t *testing.T) {
+		_, server := newFakeVault(t)
+		vault := NewVaultKeyStore(server.URL, WithVaultToken("root"))
+
+		tests := []struct {
+			key     string
+			want    string
+			present bool
+		}{
+			{"myapp/database#password", "s3cret", true},
+			{"myapp/database#port", "5432", true},
+			{"myapp/database#tls.mode", "verify-full", true},
+			{"myapp/database#tls", `{"mode":"verify-full"}`, true},
+			{"myapp/database#missing", "", false},
+			{"myapp/missing#password", "", false},
+			{"NOT_A_REFERENCE", "", false},
+		}
+		for _, tt := range tests {
+			value, ok, err := vault.Lookup(ctx, tt.key)
+			if err != nil || ok != tt.present || value != tt.want {
+				t.Errorf("Lookup(%s) = (%q, %v, %v), want (%q, %v)", tt.key, value, ok, err, tt.want, tt.present)
+			}
+		}
+	})
+
+	t.Run("Each path is fetched once per Load", func(t *testing.T) {
+		fake, server := newFakeVault(t)
+		vault := NewVaultKeyStore(server.URL, WithVaultToken("root"),
+			WithVaultKeyMapping(func(key string) string { return "myapp/database#" + key }))
+
+		type Config struct {
+			Username string `key:"username"`
+			Password string `key:"password"`
+			Port     int    `key:"port"`
+		}
+		var cfg Config
+		if err := Load(ctx, &cfg, WithKeyStore(vault.Lookup)); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Username != "app" || cfg.Password != "s3cret" || cfg.Port != 5432 {
+			t.Errorf("Got %+v", cfg)
+		}
+		if reads := fake.reads["myapp/database"]; reads != 1 {
+			t.Errorf("Expected 1 read, got %d", reads)
+		}
+
+		if err := Load(ctx, &cfg, WithKeyStore(vault.Lookup)); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if reads := fake.reads["myapp/database"]; reads != 2 {
+			t.Errorf("Expected a second Load to read again, got %d reads", reads)
+		}
+	})
+
+	t.Run("AppRole login and renewal", func(t *testing.T) {
+		fake, server := newFakeVault(t)
+		vault := NewVaultKeyStore(server.URL, WithVaultAppRoleAuth("role", "secret"))
+		now := time.Now()
+		vault.now = func() time.Time { return now }
+
+		if _, ok, err := vault.Lookup(ctx, "myapp/api#API_KEY"); err != nil || !ok {
+			t.Fatalf("Lookup() = (%v, %v)", ok, err)
+		}
+		if len(fake.logins) != 1 || fake.logins[0]["role_id"] != "role" || fake.logins[0]["secret_id"] != "secret" {
+			t.Fatalf("Unexpected logins %v", fake.logins)
+		}
+
+		now = now.Add(30 * time.Minute)
+		if _, _, err := vault.Lookup(ctx, "myapp/api#API_KEY"); err != nil {
+			t.Fatal(err)
+		}
+		if fake.renewals != 0 {
+			t.Errorf("Expected no renewal before two thirds of the lease, got %d", fake.renewals)
+		}
+
+		now = now.Add(15 * time.Minute)
+		if _, _, err := vault.Lookup(ctx, "myapp/api#API_KEY"); err != nil {
+			t.Fatal(err)
+		}
+		if fake.renewals != 1 || len(fake.logins) != 1 {
+			t.Errorf("Expected one renewal and no new login, got %d renewals and %d logins", fake.renewals, len(fake.logins))
+		}
+	})
+
+	t.Run("Logs in again when the token cannot be renewed", func(t *testing.T) {
+		fake, server := newFakeVault(t)
+		fake.renewable = false
+		vault := NewVaultKeyStore(server.URL, WithVaultAppRoleAuth("role", "secret"))
+		now := time.Now()
+		vault.now = func() time.Time { return now }
+
+		_, _, _ = vault.Lookup(ctx, "myapp/api#API_KEY")
+		now = now.Add(50 * time.Minute)
+		if _, ok, err := vault.Lookup(ctx, "myapp/api#API_KEY"); err != nil || !ok {
+			t.Fatalf("Lookup() = (%v, %v)", ok, err)
+		}
+		if len(fake.logins) != 2 || fake.renewals != 0 {
+			t.Errorf("Expected 2 logins and no renewals, got %d logins and %d renewals", len(fake.logins), fake.renewals)
+		}
+	})
+
+	t.Run("Logs in again when the token is revoked", func(t *testing.T) {
+		fake, server := newFakeVault(t)
+		vault := NewVaultKeyStore(server.URL, WithVaultAppRoleAuth("role", "secret"))
+
+		_, _, _ = vault.Lookup(ctx, "myapp/api#API_KEY")
+		fake.mu.Lock()
+		fake.tokens = map[string]bool{}
+		fake.mu.Unlock()
+		if _, ok, err := vault.Lookup(ctx, "myapp/api#API_KEY"); err != nil || !ok {
+			t.Fatalf("Lookup() = (%v, %v)", ok, err)
+		}
+		if len(fake.logins) != 2 {
+			t.Errorf("Expected 2 logins, got %d", len(fake.logins))
+		}
+	})
+
+	t.Run("Lookups share a login without holding the lock", func(t *testing.T) {
+		fake, _ := newFakeVault(t)
+		loginStarted := make(chan struct{}, 1)
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/login") {
+				loginStarted <- struct{}{}
+				<-release
+			}
+			fake.ServeHTTP(w, r)
+		}))
+		t.Cleanup(server.Close)
+		vault := NewVaultKeyStore(server.URL, WithVaultAppRoleAuth("role", "secret"))
+
+		errs := make(chan error, 3)
+		for range 3 {
+			go func() {
+				_, _, err := vault.Lookup(ctx, "myapp/api#API_KEY")
+				errs <- err
+			}()
+		}
+		<-loginStarted
+
+		// A lookup that gives up must not wait for the login to finish
+		timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if _, _, err := vault.Lookup(timeout, "myapp/database#username"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the lookup to time out, got %v", err)
+		}
+
+		close(release)
+		for range 3 {
+			if err := <-errs; err != nil {
+				t.Errorf("Lookup() error = %v", err)
+			}
+		}
+		if len(fake.logins) != 1 {
+			t.Errorf("Expected 1 login, got %d", len(fake.logins))
+		}
+	})
+
+	t.Run("Rejects oversized responses", func(t *testing.T) {
+		fake, server := newFakeVault(t)
+		fake.secrets["myapp/large"] = map[string]any{"blob": strings.Repeat("x", vaultMaxResponseSize)}
+		vault := NewVaultKeyStore(server.URL, WithVaultToken("root"))
+		if _, _, err := vault.Lookup(ctx, "myapp/large#blob"); err == nil || !strings.Contains(err.Error(), "larger than") {
+			t.Errorf("Expected size error, got %v", err)
+		}
+	})
+
+	t.Run("Kubernetes login", func(t *testing.T) {
+		fake, server := newFakeVault(t)
+		tokenPath := filepath.Join(t.TempDir(), "token")
+		if err := os.WriteFile(tokenPath, []byte("service-account-jwt\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		vault := NewVaultKeyStore(server.URL, WithVaultKubernetesAuth("myapp", tokenPath))
+
+		if value, ok, err := vault.Lookup(ctx, "myapp/api#API_KEY"); err != nil || !ok || value != "abc123" {
+			t.Fatalf("Lookup() = (%q, %v, %v)", value, ok, err)
+		}
+		if len(fake.logins) != 1 || fake.logins[0]["role"] != "myapp" || fake.logins[0]["jwt"] != "service-account-jwt" {
+			t.Errorf("Unexpected logins %v", fake.logins)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, server := newFakeVault(t)
+
+		tests := []struct {
+			name       string
+			store      *VaultKeyStore
+			key        string
+			wantErr    string
+			wantStatus int
+		}{
+			{"Server error", NewVaultKeyStore(server.URL, WithVaultToken("root")), "broken#field", "500 Internal Server Error: internal error", http.StatusInternalServerError},
+			{"Permission denied", NewVaultKeyStore(server.URL, WithVaultToken("bad")), "myapp/api#API_KEY", "permission denied", http.StatusForbidden},
+			{"Login failure", NewVaultKeyStore(server.URL, WithVaultAppRoleAuth("role", "wrong")), "myapp/api#API_KEY", "invalid role or secret ID", http.StatusBadRequest},
+			{"No token", NewVaultKeyStore(server.URL, WithVaultToken("")), "myapp/api#API_KEY", "no vault token", 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, err := tt.store.Lookup(ctx, tt.key)
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if strings.Contains(err.Error(), "abc123") {
+					t.Errorf("Error must not contain the secret: %v", err)
+				}
+				if configErr, ok := err.(ConfigError); !ok || configErr.Key != tt.key {
+					t.Errorf("Expected ConfigError for %s, got %#v", tt.key, err)
+				}
+				var statusErr *HTTPStatusError
+				if errors.As(err, &statusErr) != (tt.wantStatus != 0) || (tt.wantStatus != 0 && statusErr.StatusCode != tt.wantStatus) {
+					t.Errorf("Expected HTTPStatusError with status %d, got %v", tt.wantStatus, err)
+				}
+			})
+		}
+	})
+
+	t.Run("Honours the context", func(t *testing.T) {
+		_, server := newFakeVault(t)
+		vault := NewVaultKeyStore(server.URL, WithVaultToken("root"))
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, _, err := vault.Lookup(cancelled, "myapp/api#API_KEY"); err == nil {
+			t.Error("Expected error for cancelled context")
+		}
+	})
+}